@@ -0,0 +1,394 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+/*
+Package campaign dials lists of destinations through the plivo package,
+pacing calls, capping the number of live calls, retrying busy and unanswered
+destinations and keeping to calling hours:
+
+  c := &campaign.Campaign{
+    Client:        client,
+    Params:        plivo.CallMakeParams{From: "14155550100", AnswerURL: "https://example.com/reminder"},
+    Destinations:  campaign.Numbers(numbers...),
+    MaxConcurrent: 10,
+    CPS:           2,
+    Retry: map[plivo.CallState][]time.Duration{
+      plivo.CallBusy:     {5 * time.Minute, 30 * time.Minute},
+      plivo.CallNoAnswer: {time.Hour},
+    },
+    Hours: &campaign.Hours{Start: 9 * time.Hour, End: 20 * time.Hour},
+  }
+  outcomes, err := c.Run(ctx)
+*/
+package campaign
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/micrypt/go-plivo/plivo"
+)
+
+// Destination is a number to call.
+type Destination struct {
+	Number plivo.PhoneNumber
+	// Location is the time zone of the destination, used for calling hours.
+	// If nil, Campaign.Location is used.
+	Location *time.Location
+}
+
+// Numbers returns destinations for numbers in the campaign's default time zone.
+func Numbers(numbers ...plivo.PhoneNumber) []Destination {
+	ds := make([]Destination, len(numbers))
+	for i, n := range numbers {
+		ds[i] = Destination{Number: n}
+	}
+	return ds
+}
+
+// Hours is a daily window of calling hours, given as wall-clock offsets from
+// local midnight, in which calls may start. A call is allowed at a local time
+// t if Start <= t < End. A window with Start after End crosses midnight, as
+// in {Start: 22 * time.Hour, End: 6 * time.Hour}. Offsets are taken as clock
+// readings, so 9 * time.Hour is 09:00 also on days when clocks change.
+type Hours struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// validate checks that h is a non-empty window within a day.
+func (h *Hours) validate() error {
+	const day = 24 * time.Hour
+	if h.Start < 0 || h.Start >= day || h.End < 0 || h.End > day || h.Start == h.End {
+		return fmt.Errorf("campaign: invalid calling hours %v to %v", h.Start, h.End)
+	}
+	return nil
+}
+
+// next returns the earliest time at or after t within h in loc.
+func (h *Hours) next(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	var next time.Time
+	// Yesterday's window may cross midnight into today.
+	for day := d - 1; day <= d+1; day++ {
+		start, end := clock(y, m, day, h.Start, loc), clock(y, m, day, h.End, loc)
+		if h.End <= h.Start {
+			end = clock(y, m, day+1, h.End, loc)
+		}
+		if !t.Before(start) && t.Before(end) {
+			return t
+		}
+		if start.After(t) && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+	return next
+}
+
+// clock returns the time the clocks of loc show offset after midnight on the given day.
+func clock(y int, m time.Month, d int, offset time.Duration, loc *time.Location) time.Time {
+	return time.Date(y, m, d, int(offset/time.Hour), int(offset%time.Hour/time.Minute),
+		int(offset%time.Minute/time.Second), int(offset%time.Second), loc)
+}
+
+// Outcome is the result of calling a destination.
+type Outcome struct {
+	Destination Destination
+	// Attempts is the number of calls made to the destination.
+	Attempts int
+	// State is the final state of the last call, if one was made.
+	State plivo.CallState
+	// Call is the detail record of the last call, if it ended.
+	Call *plivo.Call
+	// Err is the error that ended the last attempt, such as a *plivo.CallError
+	// for a busy destination, or nil if the call completed.
+	Err error
+}
+
+// Campaign calls each of Destinations once, retrying as configured. The
+// zero values of its limits mean no limit.
+type Campaign struct {
+	Client *plivo.Client
+
+	// Params is the template of every call. Its To field is replaced by the
+	// destination number.
+	Params       plivo.CallMakeParams
+	Destinations []Destination
+
+	// MaxConcurrent caps the number of live calls on the account, as
+	// reported by GetAllLive, including calls not made by the campaign and
+	// campaign calls made but not yet live.
+	MaxConcurrent int
+	// CPS caps the number of calls started per second.
+	CPS float64
+	// Retry gives, for each failed state such as plivo.CallBusy or
+	// plivo.CallNoAnswer, the delays before successive retries.
+	Retry map[plivo.CallState][]time.Duration
+	// Hours restricts when calls may start, in the destination's time zone.
+	Hours *Hours
+	// Location is the default time zone of destinations, UTC if nil.
+	Location *time.Location
+	// PollInterval is how often to recheck the live call count while at
+	// MaxConcurrent. It defaults to one second.
+	PollInterval time.Duration
+
+	// OnOutcome, if set, is called with each outcome as it is decided.
+	OnOutcome func(Outcome)
+}
+
+// attempt is a pending call to a destination.
+type attempt struct {
+	index int
+	at    time.Time
+}
+
+type schedule []attempt
+
+func (s schedule) Len() int            { return len(s) }
+func (s schedule) Less(i, j int) bool  { return s[i].at.Before(s[j].at) }
+func (s schedule) Swap(i, j int)       { s[i], s[j] = s[j], s[i] }
+func (s *schedule) Push(x interface{}) { *s = append(*s, x.(attempt)) }
+func (s *schedule) Pop() interface{} {
+	old := *s
+	a := old[len(old)-1]
+	*s = old[:len(old)-1]
+	return a
+}
+
+// result is the end of a call started by the campaign.
+type result struct {
+	index int
+	call  *plivo.Call
+	err   error
+}
+
+// Run calls every destination and returns their outcomes, in the order of
+// Destinations, once all are decided or ctx is done. In the latter case
+// undecided outcomes carry the context's error, which Run also returns.
+//
+// A failure to count live calls for MaxConcurrent, or to poll a call in
+// progress, is retried at the next poll, unless the credentials are refused;
+// a call whose polling failed may still be up, so it is neither decided nor
+// retried. Calls still in progress when Run returns are no longer watched.
+func (c *Campaign) Run(ctx context.Context) ([]Outcome, error) {
+	if c.Client == nil {
+		return nil, errors.New("campaign: no client")
+	}
+	if c.Hours != nil {
+		if err := c.Hours.validate(); err != nil {
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	outcomes := make([]Outcome, len(c.Destinations))
+	pending := &schedule{}
+	now := time.Now()
+	for i, d := range c.Destinations {
+		outcomes[i].Destination = d
+		heap.Push(pending, attempt{i, c.allowed(d, now)})
+	}
+
+	var limiter *plivo.RateLimiter
+	if c.CPS > 0 {
+		limiter = plivo.NewRateLimiter(c.CPS, 1)
+	}
+	poll := c.PollInterval
+	if poll <= 0 {
+		poll = time.Second
+	}
+
+	results := make(chan result, len(c.Destinations))
+	inflight := map[int]string{} // destination index to request UUID
+	decide := func(i int, err error) {
+		outcomes[i].Err = err
+		if c.OnOutcome != nil {
+			c.OnOutcome(outcomes[i])
+		}
+	}
+
+	for pending.Len() > 0 || len(inflight) > 0 {
+		var timer *time.Timer
+		var wake <-chan time.Time
+		if pending.Len() > 0 {
+			wait := time.Until((*pending)[0].at)
+			if wait <= 0 {
+				ok, err := c.capacity(ctx, inflight)
+				if plivo.IsUnauthorized(err) {
+					return c.abort(outcomes, pending, inflight, err)
+				}
+				if ok {
+					a := heap.Pop(pending).(attempt)
+					if limiter != nil {
+						if err := limiter.Wait(ctx); err != nil {
+							heap.Push(pending, a)
+							return c.abort(outcomes, pending, inflight, err)
+						}
+					}
+					uuid, err := c.dial(ctx, &outcomes[a.index])
+					if err != nil {
+						decide(a.index, err)
+					} else {
+						inflight[a.index] = uuid
+						c.watch(ctx, a.index, uuid, 0, results)
+					}
+					continue
+				}
+				wait = poll
+			}
+			timer = time.NewTimer(wait)
+			wake = timer.C
+		}
+
+		select {
+		case r := <-results:
+			if timer != nil {
+				timer.Stop()
+			}
+			if ctx.Err() != nil {
+				// The wait was cut short; the call is undecided.
+				return c.abort(outcomes, pending, inflight, ctx.Err())
+			}
+			var cerr *plivo.CallError
+			if r.err != nil && !errors.As(r.err, &cerr) {
+				// Polling failed; the call may still be up.
+				if plivo.IsUnauthorized(r.err) {
+					return c.abort(outcomes, pending, inflight, r.err)
+				}
+				c.watch(ctx, r.index, inflight[r.index], poll, results)
+				continue
+			}
+			delete(inflight, r.index)
+			o := &outcomes[r.index]
+			o.Call = r.call
+			o.State = state(r.call, r.err)
+			if delays := c.Retry[o.State]; o.Attempts <= len(delays) && r.err != nil && ctx.Err() == nil {
+				at := time.Now().Add(delays[o.Attempts-1])
+				heap.Push(pending, attempt{r.index, c.allowed(o.Destination, at)})
+				continue
+			}
+			decide(r.index, r.err)
+		case <-wake:
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return c.abort(outcomes, pending, inflight, ctx.Err())
+		}
+	}
+	return outcomes, nil
+}
+
+// dial starts a call to the destination of o and returns its request UUID.
+func (c *Campaign) dial(ctx context.Context, o *Outcome) (string, error) {
+	p := c.Params
+	p.To = o.Destination.Number
+	o.Attempts++
+	body, _, err := c.Client.Call.Make(ctx, &p)
+	if err != nil {
+		o.State = plivo.CallFailed
+		return "", err
+	}
+	if len(body.RequestUUID) == 0 {
+		o.State = plivo.CallFailed
+		return "", errors.New("campaign: no request UUID for " + string(p.To))
+	}
+	return body.RequestUUID[0], nil
+}
+
+// watch waits in the background, after delay, for the end of the call to
+// destination i and sends it to results.
+func (c *Campaign) watch(ctx context.Context, i int, uuid string, delay time.Duration, results chan<- result) {
+	go func() {
+		if delay > 0 {
+			t := time.NewTimer(delay)
+			defer t.Stop()
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				results <- result{i, nil, ctx.Err()}
+				return
+			}
+		}
+		call, err := c.Client.Call.WaitForCall(ctx, uuid)
+		results <- result{i, call, err}
+	}()
+}
+
+// capacity reports whether another call may start with the inflight
+// campaign calls in progress. It reports false along with any error counting
+// live calls.
+func (c *Campaign) capacity(ctx context.Context, inflight map[int]string) (bool, error) {
+	if c.MaxConcurrent <= 0 {
+		return true, nil
+	}
+	if len(inflight) >= c.MaxConcurrent {
+		return false, nil
+	}
+	live, resp, err := c.Client.Call.GetAllLive(ctx)
+	if err != nil {
+		return false, err
+	}
+	n := int64(len(live))
+	if resp != nil && resp.Meta != nil && resp.Meta.TotalCount > n {
+		n = resp.Meta.TotalCount
+	}
+	// Campaign calls not live yet, such as those still queued, count too.
+	// The UUID of a call made through the API is its request UUID.
+	listed := make(map[string]bool, len(live))
+	for _, call := range live {
+		listed[call.CallUUID] = true
+	}
+	for _, uuid := range inflight {
+		if !listed[uuid] {
+			n++
+		}
+	}
+	return n < int64(c.MaxConcurrent), nil
+}
+
+// allowed returns the earliest time at or after t when d may be called.
+func (c *Campaign) allowed(d Destination, t time.Time) time.Time {
+	if c.Hours == nil {
+		return t
+	}
+	loc := d.Location
+	if loc == nil {
+		loc = c.Location
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	return c.Hours.next(t, loc)
+}
+
+// abort marks the outcomes of destinations still pending or in progress with
+// err. The state and call of an attempt in progress are cleared, since they
+// belong to the previous attempt, if any.
+func (c *Campaign) abort(outcomes []Outcome, pending *schedule, inflight map[int]string, err error) ([]Outcome, error) {
+	for _, a := range *pending {
+		outcomes[a.index].Err = err
+	}
+	for i := range inflight {
+		o := &outcomes[i]
+		o.State, o.Call, o.Err = "", nil, err
+	}
+	return outcomes, err
+}
+
+// state returns the final state of a call which ended with err, either nil
+// or a *plivo.CallError.
+func state(call *plivo.Call, err error) plivo.CallState {
+	var cerr *plivo.CallError
+	switch {
+	case errors.As(err, &cerr):
+		return cerr.State
+	case call != nil:
+		return call.State()
+	}
+	return plivo.CallCompleted
+}
@@ -0,0 +1,367 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package campaign
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/micrypt/go-plivo/plivo"
+	"github.com/micrypt/go-plivo/plivotest"
+)
+
+// operator ends the live calls of srv with the hangup causes scripted for
+// each destination, one per attempt, and records the peak number of live calls.
+// An empty cause leaves the call live.
+func operator(ctx context.Context, srv *plivotest.Server, script map[plivo.PhoneNumber][]string) (peak func() int) {
+	var mu sync.Mutex
+	max := 0
+	go func() {
+		for ctx.Err() == nil {
+			srv.Mu.Lock()
+			var calls []*plivo.LiveCall
+			for _, lc := range srv.LiveCalls {
+				calls = append(calls, lc)
+			}
+			srv.Mu.Unlock()
+
+			mu.Lock()
+			if len(calls) > max {
+				max = len(calls)
+			}
+			mu.Unlock()
+			for _, lc := range calls {
+				cause := "Normal Hangup"
+				if causes := script[lc.To]; len(causes) > 0 {
+					if causes[0] == "" {
+						continue
+					}
+					cause, script[lc.To] = causes[0], causes[1:]
+				}
+				srv.EndCall(lc.CallUUID, cause)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+	return func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return max
+	}
+}
+
+func TestRun(t *testing.T) {
+	srv := plivotest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	peak := operator(ctx, srv, map[plivo.PhoneNumber][]string{
+		"14155550102": {"Busy Line"},
+		"14155550103": {"No Answer", "No Answer"},
+	})
+
+	var mu sync.Mutex
+	reported := 0
+	c := &Campaign{
+		Client:        srv.Client(),
+		Params:        plivo.CallMakeParams{From: "14155550100", AnswerURL: "http://example.com/"},
+		Destinations:  Numbers("14155550101", "14155550102", "14155550103"),
+		MaxConcurrent: 2,
+		CPS:           100,
+		Retry: map[plivo.CallState][]time.Duration{
+			plivo.CallBusy:     {10 * time.Millisecond},
+			plivo.CallNoAnswer: {10 * time.Millisecond},
+		},
+		PollInterval: 5 * time.Millisecond,
+		OnOutcome: func(Outcome) {
+			mu.Lock()
+			reported++
+			mu.Unlock()
+		},
+	}
+	outcomes, err := c.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := []struct {
+		attempts int
+		state    plivo.CallState
+	}{
+		{1, plivo.CallCompleted},
+		{2, plivo.CallCompleted},
+		{2, plivo.CallNoAnswer},
+	}
+	for i, o := range outcomes {
+		if o.Attempts != want[i].attempts || o.State != want[i].state {
+			t.Errorf("outcome %s = %d attempts, %s; want %d, %s", o.Destination.Number, o.Attempts, o.State, want[i].attempts, want[i].state)
+		}
+	}
+	if !errors.Is(outcomes[2].Err, plivo.ErrCallNoAnswer) || outcomes[0].Err != nil {
+		t.Errorf("outcome errors = %v, %v", outcomes[0].Err, outcomes[2].Err)
+	}
+	if reported != 3 {
+		t.Errorf("OnOutcome called %d times, want 3", reported)
+	}
+	if p := peak(); p > 2 {
+		t.Errorf("%d live calls at once, want at most 2", p)
+	}
+}
+
+func TestRunHours(t *testing.T) {
+	srv := plivotest.NewServer()
+	defer srv.Close()
+
+	// Calling hours which have just ended everywhere.
+	now := time.Now().UTC()
+	since := time.Duration(now.Hour())*time.Hour + time.Duration(now.Minute())*time.Minute
+	c := &Campaign{
+		Client:       srv.Client(),
+		Params:       plivo.CallMakeParams{From: "14155550100", AnswerURL: "http://example.com/"},
+		Destinations: Numbers("14155550101"),
+		Hours:        &Hours{Start: 0, End: since},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	outcomes, err := c.Run(ctx)
+	if err != context.DeadlineExceeded || outcomes[0].Attempts != 0 || outcomes[0].Err != err {
+		t.Errorf("Run = %+v, %v; want no attempts before calling hours", outcomes, err)
+	}
+	if len(srv.LiveCalls) != 0 {
+		t.Errorf("Run called outside calling hours")
+	}
+}
+
+func TestHoursNext(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	day := &Hours{Start: 9 * time.Hour, End: 20 * time.Hour}
+	night := &Hours{Start: 22 * time.Hour, End: 6 * time.Hour}
+	for _, tt := range []struct {
+		h        *Hours
+		at, want string
+	}{
+		{day, "2014-03-24 08:00", "2014-03-24 09:00"},
+		{day, "2014-03-24 12:30", "2014-03-24 12:30"},
+		{day, "2014-03-24 20:00", "2014-03-25 09:00"},
+		{day, "2014-03-24 23:59", "2014-03-25 09:00"},
+		// Clocks go forward at 02:00 on 9 March and back on 2 November.
+		{day, "2014-03-09 08:00", "2014-03-09 09:00"},
+		{day, "2014-03-09 09:30", "2014-03-09 09:30"},
+		{day, "2014-11-02 08:30", "2014-11-02 09:00"},
+		{day, "2014-11-02 19:30", "2014-11-02 19:30"},
+		{day, "2014-11-02 20:00", "2014-11-03 09:00"},
+		{night, "2014-03-24 12:00", "2014-03-24 22:00"},
+		{night, "2014-03-24 23:00", "2014-03-24 23:00"},
+		{night, "2014-03-25 03:00", "2014-03-25 03:00"},
+		{night, "2014-03-25 06:00", "2014-03-25 22:00"},
+	} {
+		at, _ := time.ParseInLocation("2006-01-02 15:04", tt.at, ny)
+		want, _ := time.ParseInLocation("2006-01-02 15:04", tt.want, ny)
+		if got := tt.h.next(at.UTC(), ny); !got.Equal(want) {
+			t.Errorf("%v.next(%s) = %s, want %s", *tt.h, tt.at, got.In(ny), tt.want)
+		}
+	}
+
+	for _, h := range []Hours{{9 * time.Hour, 9 * time.Hour}, {-time.Hour, 5 * time.Hour}, {0, 25 * time.Hour}} {
+		c := &Campaign{Client: plivo.NewClient(nil, "id", "token"), Hours: &h}
+		if _, err := c.Run(context.Background()); err == nil {
+			t.Errorf("Run accepted calling hours %v", h)
+		}
+	}
+}
+
+func TestRunAbortInFlight(t *testing.T) {
+	srv := plivotest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	// The retry of the busy destination is still ringing when ctx ends.
+	operator(ctx, srv, map[plivo.PhoneNumber][]string{"14155550102": {"Busy Line", ""}})
+
+	c := &Campaign{
+		Client:       srv.Client(),
+		Params:       plivo.CallMakeParams{From: "14155550100", AnswerURL: "http://example.com/"},
+		Destinations: Numbers("14155550101", "14155550102"),
+		Retry:        map[plivo.CallState][]time.Duration{plivo.CallBusy: {time.Millisecond}},
+	}
+	outcomes, err := c.Run(ctx)
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Run returned %v, want deadline exceeded", err)
+	}
+	if o := outcomes[0]; o.State != plivo.CallCompleted || o.Err != nil {
+		t.Errorf("completed outcome = %+v", o)
+	}
+	if o := outcomes[1]; o.Attempts != 2 || o.State != "" || o.Call != nil || o.Err != err {
+		t.Errorf("outcome in flight = %+v; want 2 attempts, no state and the context's error", o)
+	}
+}
+
+// flakyTransport fails the first request for live calls with 503 Service Unavailable.
+type flakyTransport struct {
+	mu     sync.Mutex
+	failed bool
+}
+
+func (f *flakyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	fail := !f.failed && r.URL.Query().Get("status") == "live"
+	f.failed = f.failed || fail
+	f.mu.Unlock()
+	if fail {
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"error": "unavailable"}`)),
+			Request:    r,
+		}, nil
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestRunLiveCountError(t *testing.T) {
+	srv := plivotest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	operator(ctx, srv, nil)
+
+	flaky := &flakyTransport{}
+	client := plivo.NewClient(&http.Client{Transport: flaky}, plivotest.AuthID, plivotest.AuthToken)
+	client.BaseURL, _ = url.Parse(srv.URL + "/v1/Account/")
+	client.RetryPolicy = nil
+	c := &Campaign{
+		Client:        client,
+		Params:        plivo.CallMakeParams{From: "14155550100", AnswerURL: "http://example.com/"},
+		Destinations:  Numbers("14155550101"),
+		MaxConcurrent: 1,
+		PollInterval:  time.Millisecond,
+	}
+	outcomes, err := c.Run(ctx)
+	if err != nil || !flaky.failed || outcomes[0].State != plivo.CallCompleted {
+		t.Errorf("Run = %+v, %v; want the call made after a failed live count", outcomes, err)
+	}
+}
+
+// queuedTransport reports two external live calls and none of the campaign's,
+// as if all of its calls were still queued.
+type queuedTransport struct{}
+
+func (queuedTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Query().Get("status") == "live" && strings.HasSuffix(r.URL.Path, "/Call/") {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"meta": {"total_count": 2}, "objects": [{"call_uuid": "ext1"}, {"call_uuid": "ext2"}]}`)),
+			Request:    r,
+		}, nil
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestRunQueuedCalls(t *testing.T) {
+	srv := plivotest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	peak := operator(ctx, srv, nil)
+
+	client := plivo.NewClient(&http.Client{Transport: queuedTransport{}}, plivotest.AuthID, plivotest.AuthToken)
+	client.BaseURL, _ = url.Parse(srv.URL + "/v1/Account/")
+	c := &Campaign{
+		Client:        client,
+		Params:        plivo.CallMakeParams{From: "14155550100", AnswerURL: "http://example.com/"},
+		Destinations:  Numbers("14155550101", "14155550102", "14155550103"),
+		MaxConcurrent: 3,
+		PollInterval:  time.Millisecond,
+	}
+	outcomes, err := c.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	for _, o := range outcomes {
+		if o.State != plivo.CallCompleted {
+			t.Errorf("outcome %s = %s, want completed", o.Destination.Number, o.State)
+		}
+	}
+	if p := peak(); p > 1 {
+		t.Errorf("%d campaign calls at once beside 2 external ones, want at most 1", p)
+	}
+}
+
+// pollFailTransport fails the first poll of a live call with 503 Service Unavailable.
+type pollFailTransport struct {
+	mu     sync.Mutex
+	failed bool
+}
+
+func (f *pollFailTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	fail := !f.failed && r.URL.Query().Get("status") == "live" && !strings.HasSuffix(r.URL.Path, "/Call/")
+	f.failed = f.failed || fail
+	f.mu.Unlock()
+	if fail {
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"error": "unavailable"}`)),
+			Request:    r,
+		}, nil
+	}
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func (f *pollFailTransport) done() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failed
+}
+
+func TestRunPollError(t *testing.T) {
+	srv := plivotest.NewServer()
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	flaky := &pollFailTransport{}
+	client := plivo.NewClient(&http.Client{Transport: flaky}, plivotest.AuthID, plivotest.AuthToken)
+	client.BaseURL, _ = url.Parse(srv.URL + "/v1/Account/")
+	client.RetryPolicy = nil
+	c := &Campaign{
+		Client:       client,
+		Params:       plivo.CallMakeParams{From: "14155550100", AnswerURL: "http://example.com/"},
+		Destinations: Numbers("14155550101"),
+		Retry:        map[plivo.CallState][]time.Duration{plivo.CallFailed: {time.Millisecond}},
+		PollInterval: time.Millisecond,
+	}
+	// End the call some time after the failed poll, which must not end it.
+	go func() {
+		for ctx.Err() == nil && !flaky.done() {
+			time.Sleep(time.Millisecond)
+		}
+		time.Sleep(20 * time.Millisecond)
+		srv.Mu.Lock()
+		var live []string
+		for uuid := range srv.LiveCalls {
+			live = append(live, uuid)
+		}
+		srv.Mu.Unlock()
+		for _, uuid := range live {
+			srv.HangupCall(uuid)
+		}
+	}()
+	outcomes, err := c.Run(ctx)
+	if err != nil || !flaky.done() || outcomes[0].Attempts != 1 || outcomes[0].State != plivo.CallCompleted {
+		t.Errorf("Run = %+v, %v; want one completed call after a failed poll", outcomes, err)
+	}
+}
@@ -3,15 +3,17 @@
 
 package plivo
 
+import "context"
+
 type AccountService struct {
 	client *Client
 }
 
 type Plan struct {
-	VoiceRate           string `json:"voice_rate,omitempty"`
-	MessagingRate       string `json:"messaging_rate,omitempty"`
-	Name                string `json:"name_rate,omitempty"`
-	MonthlyCloudCredits string `json:"voice_rate,omitempty"`
+	VoiceRate           Money  `json:"voice_rate,omitzero"`
+	MessagingRate       Money  `json:"messaging_rate,omitzero"`
+	Name                string `json:"name,omitempty"`
+	MonthlyCloudCredits Money  `json:"monthly_cloud_credits,omitzero"`
 }
 
 type Account struct {
@@ -20,16 +22,16 @@ type Account struct {
 	AuthID          string `json:"auth_id,omitempty"`
 	AutoRecharge    bool   `json:"auto_recharge,omitempty"`
 	BillingMode     string `json:"billing_mode,omitempty"`
-	CashCredits     string `json:"cash_credits,omitempty"`
-	CloudCredits    string `json:"cloud_credits,omitempty"`
+	CashCredits     Money  `json:"cash_credits,omitzero"`
+	CloudCredits    Money  `json:"cloud_credits,omitzero"`
 	City            string `json:"city,omitempty"`
 	CpsAllowed      string `json:"cps_allowed,omitempty"`
-	Created         string `json:"created,omitempty"`
+	Created         Time   `json:"created,omitzero"`
 	Enabled         bool   `json:"enabled,omitempty"`
 	GwType          string `json:"gw_type,omitempty"`
-	Modified        string `json:"modified,omitempty"`
+	Modified        Time   `json:"modified,omitzero"`
 	Name            string `json:"name,omitempty"`
-	Plan            Plan   `json:"plan,omitempty"`
+	Plan            Plan   `json:"plan,omitzero"`
 	RechargeChoices string `json:"recharge_choices,omitempty"`
 	ResourceURI     string `json:"resource_uri,omitempty"`
 	State           string `json:"state,omitempty"`
@@ -41,22 +43,22 @@ type Subaccount struct {
 	ApiID       string `json:"api_id,omitempty"`
 	AuthID      string `json:"auth_id,omitempty"`
 	AuthToken   string `json:"auth_token,omitempty"`
-	Created     string `json:"created,omitempty"`
-	Modified    string `json:"modified,omitempty"`
+	Created     Time   `json:"created,omitzero"`
+	Modified    Time   `json:"modified,omitzero"`
 	Name        string `json:"name,omitempty"`
 	Enabled     bool   `json:"enabled,omitempty"`
 	ResourceURI string `json:"resource_uri,omitempty"`
 }
 
 // Get fetches an account.
-func (s *AccountService) Get() (*Account, *Response, error) {
+func (s *AccountService) Get(ctx context.Context) (*Account, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	aResp := new(Account)
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return aResp, resp, err
 }
 
@@ -67,7 +69,7 @@ type ModifyResponseBody struct {
 }
 
 // Modify edits an account
-func (s *AccountService) Modify(acc *Account) (*Account, *Response, error) {
+func (s *AccountService) Modify(ctx context.Context, acc *Account) (*Account, *Response, error) {
 	req, err := s.client.NewRequest("POST", s.client.authID+"/", acc)
 	if err != nil {
 		return nil, nil, err
@@ -75,7 +77,7 @@ func (s *AccountService) Modify(acc *Account) (*Account, *Response, error) {
 
 	req.Header.Add("Content-Type", "application/json")
 	aResp := &ModifyResponseBody{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 
 	return acc, resp, err
 }
@@ -89,7 +91,7 @@ type CreateResponseBody struct {
 }
 
 // CreateSubaccount creates a subaccount.
-func (s *AccountService) CreateSubaccount(sacc *Subaccount) (*Response, error) {
+func (s *AccountService) CreateSubaccount(ctx context.Context, sacc *Subaccount) (*Response, error) {
 
 	req, err := s.client.NewRequest("POST", s.client.authID+"/Subaccount/", sacc)
 	if err != nil {
@@ -98,13 +100,13 @@ func (s *AccountService) CreateSubaccount(sacc *Subaccount) (*Response, error) {
 
 	aResp := &CreateResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	sacc.AuthID = aResp.AuthID
 	return resp, err
 }
 
 // ModifySubaccount edits a subaccount.
-func (s *AccountService) ModifySubaccount(sacc *Subaccount) (*Subaccount, *Response, error) {
+func (s *AccountService) ModifySubaccount(ctx context.Context, sacc *Subaccount) (*Subaccount, *Response, error) {
 	req, err := s.client.NewRequest("POST", s.client.authID+"/Subaccount/"+sacc.AuthID+"/", sacc)
 	if err != nil {
 		return nil, nil, err
@@ -112,20 +114,20 @@ func (s *AccountService) ModifySubaccount(sacc *Subaccount) (*Subaccount, *Respo
 
 	req.Header.Add("Content-Type", "application/json")
 	aResp := &ModifyResponseBody{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 
 	return sacc, resp, err
 }
 
 // GetSubaccount fetches a subaccount.
-func (s *AccountService) GetSubaccount(subAuthID string) (*Subaccount, *Response, error) {
+func (s *AccountService) GetSubaccount(ctx context.Context, subAuthID string) (*Subaccount, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Subaccount/"+subAuthID+"/", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	aResp := &Subaccount{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return aResp, resp, err
 }
 
@@ -136,7 +138,7 @@ type SubaccountsResponseBody struct {
 }
 
 // GetSubaccount fetches all subaccounts.
-func (s *AccountService) GetSubaccounts(limit, offset int64) ([]*Subaccount, *Response, error) {
+func (s *AccountService) GetSubaccounts(ctx context.Context, limit, offset int64) ([]*Subaccount, *Response, error) {
 	limitOffset := &limitOffset{limit, offset}
 
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Subaccount/", limitOffset)
@@ -146,17 +148,27 @@ func (s *AccountService) GetSubaccounts(limit, offset int64) ([]*Subaccount, *Re
 	}
 
 	aResp := &SubaccountsResponseBody{}
-	resp, err := s.client.Do(req, aResp)
-	resp.Meta = aResp.Meta
+	resp, err := s.client.Do(ctx, req, aResp)
+	if resp != nil {
+		resp.Meta = aResp.Meta
+	}
 	return aResp.Objects, resp, err
 }
 
+// GetSubaccountsPager returns a Pager which walks all subaccounts, page by page.
+func (s *AccountService) GetSubaccountsPager(ctx context.Context) *Pager[*Subaccount] {
+	return NewPager(ctx, 0, func(ctx context.Context, limit, offset int64) ([]*Subaccount, *Meta, error) {
+		objs, resp, err := s.GetSubaccounts(ctx, limit, offset)
+		return objs, pageMeta(resp), err
+	})
+}
+
 // DeleteSubaccount deletes a subaccount.
-func (s *AccountService) DeleteSubaccount(subAuthID string) (*Response, error) {
+func (s *AccountService) DeleteSubaccount(ctx context.Context, subAuthID string) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/Subaccount/"+subAuthID+"/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
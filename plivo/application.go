@@ -3,6 +3,8 @@
 
 package plivo
 
+import "context"
+
 type ApplicationService struct {
 	client *Client
 }
@@ -36,7 +38,7 @@ type ApplicationCreateResponseBody struct {
 }
 
 // CreateApplication creates an application.
-func (s *ApplicationService) Create(app *Application) (*Application, *Response, error) {
+func (s *ApplicationService) Create(ctx context.Context, app *Application) (*Application, *Response, error) {
 
 	req, err := s.client.NewRequest("POST", s.client.authID+"/Application/", app)
 
@@ -46,7 +48,7 @@ func (s *ApplicationService) Create(app *Application) (*Application, *Response,
 
 	aResp := &ApplicationCreateResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	app.AppID = aResp.AppID
 	return app, resp, err
 }
@@ -58,7 +60,7 @@ type ApplicationsResponseBody struct {
 }
 
 // GetApplication fetches all subaccounts.
-func (s *ApplicationService) GetApplications(limit, offset int64) ([]*Application, *Response, error) {
+func (s *ApplicationService) GetApplications(ctx context.Context, limit, offset int64) ([]*Application, *Response, error) {
 	limitOffset := &limitOffset{limit, offset}
 
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Application/", limitOffset)
@@ -68,25 +70,35 @@ func (s *ApplicationService) GetApplications(limit, offset int64) ([]*Applicatio
 	}
 
 	aResp := &ApplicationsResponseBody{}
-	resp, err := s.client.Do(req, aResp)
-	resp.Meta = aResp.Meta
+	resp, err := s.client.Do(ctx, req, aResp)
+	if resp != nil {
+		resp.Meta = aResp.Meta
+	}
 	return aResp.Objects, resp, err
 }
 
+// GetApplicationsPager returns a Pager which walks all applications, page by page.
+func (s *ApplicationService) GetApplicationsPager(ctx context.Context) *Pager[*Application] {
+	return NewPager(ctx, 0, func(ctx context.Context, limit, offset int64) ([]*Application, *Meta, error) {
+		objs, resp, err := s.GetApplications(ctx, limit, offset)
+		return objs, pageMeta(resp), err
+	})
+}
+
 // Get fetches a specified application.
-func (s *ApplicationService) Get(appID string) (*Application, *Response, error) {
+func (s *ApplicationService) Get(ctx context.Context, appID string) (*Application, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Application/"+appID+"/", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	aResp := &Application{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return aResp, resp, err
 }
 
 // Modify edits an application
-func (s *ApplicationService) Modify(app *Application) (*Application, *Response, error) {
+func (s *ApplicationService) Modify(ctx context.Context, app *Application) (*Application, *Response, error) {
 	req, err := s.client.NewRequest("POST", s.client.authID+"/Application/"+app.AppID+"/", app)
 	if err != nil {
 		return nil, nil, err
@@ -94,17 +106,17 @@ func (s *ApplicationService) Modify(app *Application) (*Application, *Response,
 
 	req.Header.Add("Content-Type", "application/json")
 	aResp := &ModifyResponseBody{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 
 	return app, resp, err
 }
 
 // Delete deletes a subaccount.
-func (s *ApplicationService) Delete(subAuthID string) (*Response, error) {
+func (s *ApplicationService) Delete(ctx context.Context, subAuthID string) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/Application/"+subAuthID+"/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
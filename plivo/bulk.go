@@ -0,0 +1,186 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MaxCallDestinations is the most destinations the API accepts in one Make request.
+const MaxCallDestinations = 1000
+
+// CallBulkResult reports the outcome of MakeBulk for each destination.
+type CallBulkResult struct {
+	// RequestUUID maps each destination called to the request UUID of its call.
+	RequestUUID map[PhoneNumber]string
+	// Failed maps each destination not called to the reason.
+	Failed map[PhoneNumber]error
+}
+
+// MakeBulk calls every destination in to with the parameters of cp, whose To
+// field is ignored. Destinations are sent joined with '<' in requests of at
+// most MaxCallDestinations, and each distinct destination is called once.
+//
+// Invalid destinations and those of failed requests are reported in Failed
+// without stopping the others. The returned error is the first such failure,
+// in the order of to, if any; the result is always complete.
+func (c *CallService) MakeBulk(ctx context.Context, cp *CallMakeParams, to []PhoneNumber) (*CallBulkResult, error) {
+	if cp == nil {
+		return nil, errors.New("plivo: MakeBulk with nil parameters")
+	}
+	res := &CallBulkResult{RequestUUID: map[PhoneNumber]string{}, Failed: map[PhoneNumber]error{}}
+
+	var valid []PhoneNumber
+	seen := map[PhoneNumber]bool{}
+	for _, d := range to {
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		if d == "" || strings.Contains(string(d), "<") {
+			res.Failed[d] = fmt.Errorf("plivo: invalid to number %q", d)
+			continue
+		}
+		if err := checkNumbers("to", d, true); err != nil {
+			res.Failed[d] = err
+			continue
+		}
+		valid = append(valid, d)
+	}
+
+	for _, batch := range chunk(valid, MaxCallDestinations) {
+		p := *cp
+		p.To = joinNumbers(batch)
+		body, _, err := c.Make(ctx, &p)
+		for i, d := range batch {
+			switch {
+			case err != nil:
+				res.Failed[d] = err
+			case i < len(body.RequestUUID):
+				res.RequestUUID[d] = body.RequestUUID[i]
+			default:
+				res.Failed[d] = errors.New("plivo: no request UUID returned for " + string(d))
+			}
+		}
+	}
+	return res, firstFailure(res.Failed, to)
+}
+
+// MaxMessageDestinations is the most destinations the API accepts in one Send request.
+const MaxMessageDestinations = 1000
+
+// bulkConcurrency is the most requests SendBulk has in flight at once.
+const bulkConcurrency = 4
+
+// MessageBulkResult reports the outcome of SendBulk for each destination.
+type MessageBulkResult struct {
+	// MessageUUID maps each destination sent to to the UUID of its message.
+	MessageUUID map[PhoneNumber]string
+	// Failed maps each destination not sent to to the reason.
+	Failed map[PhoneNumber]error
+}
+
+// SendBulk sends the message mp, whose Dst field is ignored, to every
+// destination in dst. Destinations are sent joined with '<' in requests of at
+// most MaxMessageDestinations, up to four requests at a time, paced by the
+// client's rate limiters. Each distinct destination is sent to once.
+//
+// Invalid destinations, those which opted out and those of failed requests
+// are reported in Failed without stopping the others. The returned error is
+// the first such failure, in the order of dst, if any; the result is always
+// complete.
+func (c *MessageService) SendBulk(ctx context.Context, mp *MessageSendParams, dst []PhoneNumber) (*MessageBulkResult, error) {
+	if mp == nil {
+		return nil, errors.New("plivo: SendBulk with nil parameters")
+	}
+	res := &MessageBulkResult{MessageUUID: map[PhoneNumber]string{}, Failed: map[PhoneNumber]error{}}
+
+	var valid []PhoneNumber
+	seen := map[PhoneNumber]bool{}
+	for _, d := range dst {
+		if seen[d] {
+			continue
+		}
+		seen[d] = true
+		if !d.Valid() {
+			res.Failed[d] = fmt.Errorf("plivo: invalid dst number %q", d)
+			continue
+		}
+		if c.client.OptOut != nil {
+			if err := c.client.OptOut.Check(ctx, d); err != nil {
+				res.Failed[d] = err
+				continue
+			}
+		}
+		valid = append(valid, d)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, bulkConcurrency)
+	for _, batch := range chunk(valid, MaxMessageDestinations) {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(batch []PhoneNumber) {
+			defer func() { <-sem; wg.Done() }()
+			p := *mp
+			p.Dst = joinNumbers(batch)
+			body, _, err := c.Send(ctx, &p)
+
+			mu.Lock()
+			defer mu.Unlock()
+			for i, d := range batch {
+				switch {
+				case err != nil:
+					res.Failed[d] = err
+				case i < len(body.MessageUUID):
+					res.MessageUUID[d] = body.MessageUUID[i]
+				default:
+					res.Failed[d] = errors.New("plivo: no message UUID returned for " + string(d))
+				}
+			}
+		}(batch)
+	}
+	wg.Wait()
+	return res, firstFailure(res.Failed, dst)
+}
+
+// joinNumbers joins destinations with '<', as the API takes bulk destinations.
+func joinNumbers(ns []PhoneNumber) PhoneNumber {
+	var b strings.Builder
+	for i, n := range ns {
+		if i > 0 {
+			b.WriteByte('<')
+		}
+		b.WriteString(string(n))
+	}
+	return PhoneNumber(b.String())
+}
+
+// firstFailure returns the error of the first of ns in failed, if any.
+func firstFailure(failed map[PhoneNumber]error, ns []PhoneNumber) error {
+	for _, n := range ns {
+		if err := failed[n]; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunk splits s into consecutive slices of at most n elements.
+func chunk[T any](s []T, n int) [][]T {
+	var chunks [][]T
+	for len(s) > n {
+		chunks = append(chunks, s[:n:n])
+		s = s[n:]
+	}
+	if len(s) > 0 {
+		chunks = append(chunks, s)
+	}
+	return chunks
+}
@@ -0,0 +1,109 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMakeBulk(t *testing.T) {
+	var requests []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p CallMakeParams
+		json.NewDecoder(r.Body).Decode(&p)
+		to := strings.Split(string(p.To), "<")
+		requests = append(requests, len(to))
+		if len(requests) == 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "too many calls"}`)
+			return
+		}
+		uuids := make([]string, len(to))
+		for i, dst := range to {
+			uuids[i] = "req-" + dst
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"request_uuid": uuids})
+	}))
+	defer server.Close()
+	client := newTestClient(server)
+
+	var to []PhoneNumber
+	for i := 0; i < MaxCallDestinations+200; i++ {
+		to = append(to, PhoneNumber(fmt.Sprintf("1415555%04d", i)))
+	}
+	to = append(to, to[0], "12", "sip:alice@example.com")
+	res, err := client.Call.MakeBulk(ctx, &CallMakeParams{From: "14155550100", AnswerURL: "http://example.com/"}, to)
+	if !IsValidation(err) || err != res.Failed["14155551000"] {
+		t.Errorf("MakeBulk returned %v, want the error of the second batch", err)
+	}
+	if len(requests) != 2 || requests[0] != MaxCallDestinations || requests[1] != 201 {
+		t.Errorf("MakeBulk made requests of %v destinations", requests)
+	}
+	if len(res.RequestUUID) != MaxCallDestinations || res.RequestUUID["14155550007"] != "req-14155550007" {
+		t.Errorf("RequestUUID has %d entries", len(res.RequestUUID))
+	}
+	if len(res.Failed) != 202 || !IsValidation(res.Failed["sip:alice@example.com"]) || res.Failed["12"] == nil {
+		t.Errorf("Failed has %d entries: %v", len(res.Failed), res.Failed["12"])
+	}
+	if _, err := client.Call.MakeBulk(ctx, nil, to); err == nil {
+		t.Errorf("MakeBulk accepted nil parameters")
+	}
+}
+
+func TestSendBulk(t *testing.T) {
+	var mu sync.Mutex
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p MessageSendParams
+		json.NewDecoder(r.Body).Decode(&p)
+		dst := strings.Split(string(p.Dst), "<")
+		mu.Lock()
+		sizes = append(sizes, len(dst))
+		mu.Unlock()
+		if dst[0] == "14155552000" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		uuids := make([]string, len(dst))
+		for i, d := range dst {
+			uuids[i] = "msg-" + d
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]interface{}{"message_uuid": uuids})
+	}))
+	defer server.Close()
+	client := newTestClient(server)
+	client.RetryPolicy = nil
+
+	var dst []string
+	for i := 0; i < 2*MaxMessageDestinations+10; i++ {
+		dst = append(dst, fmt.Sprintf("1415555%04d", i))
+	}
+	dst = append(dst, "555", dst[1])
+	res, err := client.Message.SendBulk(ctx, &MessageSendParams{Src: "14155550100", Text: "Hi"}, PhoneNumbers(dst))
+	if !IsServerError(err) {
+		t.Errorf("SendBulk returned %v, want the server error of the third batch", err)
+	}
+	sort.Ints(sizes)
+	if fmt.Sprint(sizes) != "[10 1000 1000]" {
+		t.Errorf("SendBulk sent batches of %v", sizes)
+	}
+	if len(res.MessageUUID) != 2*MaxMessageDestinations || res.MessageUUID["14155551999"] != "msg-14155551999" {
+		t.Errorf("MessageUUID has %d entries", len(res.MessageUUID))
+	}
+	if len(res.Failed) != 11 || res.Failed["555"] == nil || !IsServerError(res.Failed["14155552009"]) {
+		t.Errorf("Failed = %v", res.Failed)
+	}
+	if _, err := client.Message.SendBulk(ctx, nil, PhoneNumbers(dst)); err == nil {
+		t.Errorf("SendBulk accepted nil parameters")
+	}
+}
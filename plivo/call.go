@@ -3,56 +3,70 @@
 
 package plivo
 
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
 type CallService struct {
 	client *Client
 }
 
 type Call struct {
-	FromNumber     string `json:"from_number,omitempty"`
-	ToNumber       string `json:"to_number,omitempty"`
-	AnswerURL      string `json:"answer_url,omitempty"`
-	CallUUID       string `json:"call_uuid,omitempty"`
-	ParentCallUUID string `json:"parent_call_uuid,omitempty"`
-	EndTime        string `json:"end_time,omitempty"`
-	TotalAmount    string `json:"total_amount,omitempty"`
-	CallDirection  string `json:"call_direction,omitempty"`
-	CallDuration   int64  `json:"call_duration,omitempty"`
-	MessageURL     string `json:"message_url,omitempty"`
-	ResourceURI    string `json:"resource_uri,omitempty"`
+	FromNumber     PhoneNumber `json:"from_number,omitempty"`
+	ToNumber       PhoneNumber `json:"to_number,omitempty"`
+	AnswerURL      string      `json:"answer_url,omitempty"`
+	CallUUID       string      `json:"call_uuid,omitempty"`
+	ParentCallUUID string      `json:"parent_call_uuid,omitempty"`
+	EndTime        Time        `json:"end_time,omitzero"`
+	TotalAmount    Money       `json:"total_amount,omitzero"`
+	CallDirection  string      `json:"call_direction,omitempty"`
+	CallDuration   int64       `json:"call_duration,omitempty"`
+	BillDuration   int64       `json:"bill_duration,omitempty"`
+	AnswerTime     Time        `json:"answer_time,omitzero"`
+	CallState      string      `json:"call_state,omitempty"`
+	HangupCause    string      `json:"hangup_cause_name,omitempty"`
+	HangupSource   string      `json:"hangup_source,omitempty"`
+	MessageURL     string      `json:"message_url,omitempty"`
+	ResourceURI    string      `json:"resource_uri,omitempty"`
 }
 
 type LiveCall struct {
-	From           string `json:"from,omitempty"`
-	To             string `json:"to,omitempty"`
-	AnswerURL      string `json:"answer_url,omitempty"`
-	CallUUID       string `json:"call_uuid,omitempty"`
-	CallerName     string `json:"caller_name,omitempty"`
-	ParentCallUUID string `json:"parent_call_uuid,omitempty"`
-	SessionStart   string `json:"session_start,omitempty"`
+	From           PhoneNumber `json:"from,omitempty"`
+	To             PhoneNumber `json:"to,omitempty"`
+	AnswerURL      string      `json:"answer_url,omitempty"`
+	CallUUID       string      `json:"call_uuid,omitempty"`
+	CallerName     string      `json:"caller_name,omitempty"`
+	ParentCallUUID string      `json:"parent_call_uuid,omitempty"`
+	RequestUUID    string      `json:"request_uuid,omitempty"`
+	CallStatus     CallState   `json:"call_status,omitempty"`
+	SessionStart   Time        `json:"session_start,omitzero"`
 }
 
 type CallMakeParams struct {
 	// Required parameters.
-	From      string `json:"from,omitempty"`
-	To        string `json:"to,omitempty"`
-	AnswerURL string `json:"answer_url,omitempty"`
+	From      PhoneNumber `json:"from,omitempty"`
+	To        PhoneNumber `json:"to,omitempty"`
+	AnswerURL string      `json:"answer_url,omitempty"`
 	// Optional parameters.
-	AnswerMethod         string `json:"answer_method,omitempty"`
-	RingURL              string `json:"ring_url,omitempty"`
-	RingMethod           string `json:"ring_method,omitempty"`
-	HangupURL            string `json:"hangup_url,omitempty"`
-	HangupMethod         string `json:"hangup_method,omitempty"`
-	FallbackURL          string `json:"fallback_url,omitempty"`
-	FallbackMethod       string `json:"fallback_method,omitempty"`
-	CallerName           string `json:"caller_name,omitempty"`
-	SendDigits           string `json:"send_digits,omitempty"`
-	SendOnPreanswer      bool   `json:"send_on_preanswer,omitempty"`
-	TimeLimit            int64  `json:"time_limit,omitempty"`
-	HangupOnRing         int64  `json:"hangup_on_ring,omitempty"`
-	MachineDetection     string `json:"machine_detection,omitempty"`
-	MachineDetectionTime int64  `json:"machine_detection_time,omitempty"`
-	SipHeaders           string `json:"sip_headers,omitempty"`
-	RingTimeout          int64  `json:"ring_timeout,omitempty"`
+	AnswerMethod         string     `json:"answer_method,omitempty"`
+	RingURL              string     `json:"ring_url,omitempty"`
+	RingMethod           string     `json:"ring_method,omitempty"`
+	HangupURL            string     `json:"hangup_url,omitempty"`
+	HangupMethod         string     `json:"hangup_method,omitempty"`
+	FallbackURL          string     `json:"fallback_url,omitempty"`
+	FallbackMethod       string     `json:"fallback_method,omitempty"`
+	CallerName           string     `json:"caller_name,omitempty"`
+	SendDigits           string     `json:"send_digits,omitempty"`
+	SendOnPreanswer      bool       `json:"send_on_preanswer,omitempty"`
+	TimeLimit            int64      `json:"time_limit,omitempty"`
+	HangupOnRing         int64      `json:"hangup_on_ring,omitempty"`
+	MachineDetection     string     `json:"machine_detection,omitempty"`
+	MachineDetectionTime int64      `json:"machine_detection_time,omitempty"`
+	SipHeaders           SIPHeaders `json:"sip_headers,omitempty"`
+	RingTimeout          int64      `json:"ring_timeout,omitempty"`
 }
 
 // Stores response for making a call.
@@ -61,30 +75,70 @@ type CallMakeResponseBody struct {
 	ApiID    string `json:"api_id"`
 	AppID    string `json:"app_id"`
 	CallUUID string `json:"call_uuid"`
+	// RequestUUID identifies the call requested for each destination, in
+	// the order they were given in CallMakeParams.To.
+	RequestUUID []string `json:"request_uuid"`
+}
+
+// UnmarshalJSON accepts request_uuid either as a single string, as the API
+// returns for one destination, or as a list.
+func (b *CallMakeResponseBody) UnmarshalJSON(data []byte) error {
+	type body CallMakeResponseBody
+	var v struct {
+		*body
+		RequestUUID json.RawMessage `json:"request_uuid"`
+	}
+	v.body = (*body)(b)
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	b.RequestUUID = nil
+	if len(v.RequestUUID) == 0 || string(v.RequestUUID) == "null" {
+		return nil
+	}
+	var one string
+	if json.Unmarshal(v.RequestUUID, &one) == nil {
+		b.RequestUUID = []string{one}
+		return nil
+	}
+	return json.Unmarshal(v.RequestUUID, &b.RequestUUID)
 }
 
-// Make creates a call.
-func (c *CallService) Make(cp *CallMakeParams) (*Response, error) {
+// Make creates a call. The request UUIDs in the returned body identify the
+// call to WaitForCall.
+func (c *CallService) Make(ctx context.Context, cp *CallMakeParams) (*CallMakeResponseBody, *Response, error) {
+	if cp == nil {
+		return nil, nil, errors.New("plivo: Make with nil parameters")
+	}
+	if err := checkNumbers("from", cp.From, false); err != nil {
+		return nil, nil, err
+	}
+	if err := checkNumbers("to", cp.To, true); err != nil {
+		return nil, nil, err
+	}
+	if err := cp.SipHeaders.Validate(); err != nil {
+		return nil, nil, err
+	}
 	req, err := c.client.NewRequest("POST", c.client.authID+"/Call/", cp)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	aResp := &CallMakeResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, aResp)
-	return resp, err
+	resp, err := c.client.Do(ctx, req, aResp)
+	return aResp, resp, err
 }
 
 type CallGetAllParams struct {
 	// Query parameters.
-	Subaccount    string `json:"subaccount,omitempty"`
-	CallDirection string `json:"call_direction,omitempty"`
-	FromNumber    string `json:"from_number,omitempty"`
-	ToNumber      string `json:"to_number,omitempty"`
-	EndTime       string `json:"end_time,omitempty"`
-	BillDuration  string `json:"bill_duration,omitempty"`
-	Limit         int64  `json:"limit:omitempty"`
-	Offset        int64  `json:"offset:omitempty"`
+	Subaccount    string      `url:"subaccount,omitempty"`
+	CallDirection Direction   `url:"call_direction,omitempty"`
+	FromNumber    PhoneNumber `url:"from_number,omitempty"`
+	ToNumber      PhoneNumber `url:"to_number,omitempty"`
+	EndTime       TimeFilter  `url:"end_time,omitempty"`
+	BillDuration  IntFilter   `url:"bill_duration,omitempty"` // In seconds.
+	Limit         int64       `url:"limit,omitempty"`
+	Offset        int64       `url:"offset,omitempty"`
 }
 
 type CallGetAllResponseBody struct {
@@ -94,86 +148,185 @@ type CallGetAllResponseBody struct {
 }
 
 // GetAll fetches all calls.
-func (s *CallService) GetAll(p *CallGetAllParams) ([]*Call, *Response, error) {
+func (s *CallService) GetAll(ctx context.Context, p *CallGetAllParams) ([]*Call, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Call/", p)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &CallGetAllResponseBody{}
-	resp, err := s.client.Do(req, aResp)
-	resp.Meta = aResp.Meta
+	resp, err := s.client.Do(ctx, req, aResp)
+	if resp != nil {
+		resp.Meta = aResp.Meta
+	}
 	return aResp.Objects, resp, err
 }
 
+// GetAllPager returns a Pager which walks all calls matching p, page by page,
+// starting at p.Offset. A non-zero p.Limit sets the Pager's PageSize.
+func (s *CallService) GetAllPager(ctx context.Context, p *CallGetAllParams) *Pager[*Call] {
+	q := CallGetAllParams{}
+	if p != nil {
+		q = *p
+	}
+	pg := NewPager(ctx, q.Offset, func(ctx context.Context, limit, offset int64) ([]*Call, *Meta, error) {
+		q.Limit, q.Offset = limit, offset
+		objs, resp, err := s.GetAll(ctx, &q)
+		return objs, pageMeta(resp), err
+	})
+	pg.PageSize = q.Limit
+	return pg
+}
+
 // Get fetches a specified call.
-func (s *CallService) Get(callID string) (*Call, *Response, error) {
+func (s *CallService) Get(ctx context.Context, callID string) (*Call, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Call/"+callID+"/", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &Call{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return aResp, resp, err
 }
 
 // GetCallLive fetches all live calls.
-func (s *CallService) GetAllLive() ([]*Call, *Response, error) {
+func (s *CallService) GetAllLive(ctx context.Context) ([]*Call, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Call/?status=live", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &CallGetAllResponseBody{}
-	resp, err := s.client.Do(req, aResp)
-	resp.Meta = aResp.Meta
+	resp, err := s.client.Do(ctx, req, aResp)
+	if resp != nil {
+		resp.Meta = aResp.Meta
+	}
 	return aResp.Objects, resp, err
 }
 
 // GetLive fetches details of a specified call.
-func (s *CallService) GetLive(uuid string) (*LiveCall, *Response, error) {
+func (s *CallService) GetLive(ctx context.Context, uuid string) (*LiveCall, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Call/"+uuid+"/?status=live", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &LiveCall{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return aResp, resp, err
 }
 
 // Hangup terminates a specified call.
-func (s *CallService) Hangup(uuid string) (*Response, error) {
+func (s *CallService) Hangup(ctx context.Context, uuid string) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/Call/"+uuid+"/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
+// Legs selects the legs of a call an action applies to: the A leg is the
+// call itself and the B leg the party it is bridged to.
+type Legs string
+
+const (
+	ALeg     Legs = "aleg"
+	BLeg     Legs = "bleg"
+	BothLegs Legs = "both"
+)
+
 type CallTransferParams struct {
-	legs       string `json:"legs,omitempty"`
+	Legs       Legs   `json:"legs,omitempty"`
 	AlegURL    string `json:"aleg_url,omitempty"`
 	AlegMethod string `json:"aleg_method,omitempty"`
 	BlegURL    string `json:"bleg_url,omitempty"`
 	BlegMethod string `json:"bleg_method,omitempty"`
 }
 
+// validate checks that the URL of every transferred leg is given.
+func (cp *CallTransferParams) validate() error {
+	legs := cp.Legs
+	if legs == "" {
+		legs = ALeg
+	}
+	switch {
+	case legs != ALeg && legs != BLeg && legs != BothLegs:
+		return fmt.Errorf("plivo: invalid legs %q", cp.Legs)
+	case legs != BLeg && cp.AlegURL == "":
+		return errors.New("plivo: transfer of the A leg needs AlegURL")
+	case legs != ALeg && cp.BlegURL == "":
+		return errors.New("plivo: transfer of the B leg needs BlegURL")
+	}
+	return nil
+}
+
 type CallTransferResponseBody struct {
 	ApiID   string `json:"api_id"`
 	Message string `json:"message"`
 }
 
-// Transfer transfers a call.
-func (c *CallService) Transfer(cp *CallTransferParams) (*Response, error) {
-	req, err := c.client.NewRequest("POST", c.client.authID+"/Call/", cp)
+// Transfer transfers the legs of a live call to new XML documents.
+func (c *CallService) Transfer(ctx context.Context, uuid string, cp *CallTransferParams) (*Response, error) {
+	if cp == nil {
+		return nil, errors.New("plivo: Transfer with nil parameters")
+	}
+	if err := cp.validate(); err != nil {
+		return nil, err
+	}
+	req, err := c.client.NewRequest("POST", c.client.authID+"/Call/"+uuid+"/", cp)
 	if err != nil {
 		return nil, err
 	}
 	aResp := &CallTransferResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, aResp)
+	resp, err := c.client.Do(ctx, req, aResp)
 	return resp, err
 }
 
+// Redirect transfers the A leg of a live call to the XML document at url,
+// fetched with method, or POST if method is empty.
+func (c *CallService) Redirect(ctx context.Context, uuid, url, method string) (*Response, error) {
+	return c.Transfer(ctx, uuid, &CallTransferParams{Legs: ALeg, AlegURL: url, AlegMethod: method})
+}
+
+// Hold plays the audio at musicURL in a loop to the given legs of a live
+// call, in place of the other party, until Unhold is called.
+func (c *CallService) Hold(ctx context.Context, uuid, musicURL string, legs Legs) (*Response, error) {
+	return c.Play(ctx, uuid, &CallPlayParams{URLs: musicURL, Legs: legs, Loop: true})
+}
+
+// Unhold stops the music played by Hold.
+func (c *CallService) Unhold(ctx context.Context, uuid string) (*Response, error) {
+	return c.StopPlaying(ctx, uuid)
+}
+
+type WarmTransferParams struct {
+	// HoldMusicURL is played to the A leg while the new party is dialled.
+	HoldMusicURL string
+	// Call dials the new party. Its AnswerURL should return the XML which
+	// introduces and joins the parties, for instance a Conference which the
+	// held call is then redirected to.
+	Call CallMakeParams
+}
+
+// WarmTransfer puts the A leg of a live call on hold and dials the new party.
+// If the new call cannot be made the hold music is stopped again, even if ctx
+// is done, and any error doing so is joined to the one returned.
+func (c *CallService) WarmTransfer(ctx context.Context, uuid string, wp *WarmTransferParams) (*CallMakeResponseBody, *Response, error) {
+	if wp == nil {
+		return nil, nil, errors.New("plivo: WarmTransfer with nil parameters")
+	}
+	if resp, err := c.Hold(ctx, uuid, wp.HoldMusicURL, ALeg); err != nil {
+		return nil, resp, err
+	}
+	body, resp, err := c.Make(ctx, &wp.Call)
+	if err != nil {
+		if _, uerr := c.Unhold(context.WithoutCancel(ctx), uuid); uerr != nil {
+			err = errors.Join(err, uerr)
+		}
+		return nil, resp, err
+	}
+	return body, resp, nil
+}
+
 type CallRecordParams struct {
 	TimeLimit           int64  `json:"time_limit,omitempty"`
 	FileFormat          string `json:"file_format,omitempty"`
@@ -190,33 +343,33 @@ type CallRecordResponseBody struct {
 }
 
 // Record records a call.
-func (c *CallService) Record(uuid string, cp *CallRecordParams) (*Response, error) {
+func (c *CallService) Record(ctx context.Context, uuid string, cp *CallRecordParams) (*Response, error) {
 	req, err := c.client.NewRequest("POST", c.client.authID+"/Call/"+uuid+"/Record/", cp)
 	if err != nil {
 		return nil, err
 	}
 	aResp := &CallRecordResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, aResp)
+	resp, err := c.client.Do(ctx, req, aResp)
 	return resp, err
 }
 
 // StopRecording cancels a call recording.
-func (c *CallService) StopRecording(uuid, url string) (*Response, error) {
+func (c *CallService) StopRecording(ctx context.Context, uuid, url string) (*Response, error) {
 	rp := struct{ URL string }{url}
 	req, err := c.client.NewRequest("DELETE", c.client.authID+"/Call/"+uuid+"/Record/", rp)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, nil)
+	resp, err := c.client.Do(ctx, req, nil)
 	return resp, err
 }
 
 type CallPlayParams struct {
 	URLs   string `json:"urls"`
 	Length string `json:"length,omitempty"`
-	Legs   string `json:"legs,omitempty"`
+	Legs   Legs   `json:"legs,omitempty"`
 	Loop   bool   `json:"loop,omitempty"`
 	Mix    bool   `json:"mix,omitempty"`
 }
@@ -227,25 +380,25 @@ type CallPlayResponseBody struct {
 }
 
 // Play plays and controls sounds during a call.
-func (c *CallService) Play(uuid string, cp *CallPlayParams) (*Response, error) {
+func (c *CallService) Play(ctx context.Context, uuid string, cp *CallPlayParams) (*Response, error) {
 	req, err := c.client.NewRequest("POST", c.client.authID+"/Call/"+uuid+"/Play/", cp)
 	if err != nil {
 		return nil, err
 	}
 	aResp := &CallPlayResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, aResp)
+	resp, err := c.client.Do(ctx, req, aResp)
 	return resp, err
 }
 
 // StopPlaying stops playing sounds during a call.
-func (c *CallService) StopPlaying(uuid string) (*Response, error) {
+func (c *CallService) StopPlaying(ctx context.Context, uuid string) (*Response, error) {
 	req, err := c.client.NewRequest("DELETE", c.client.authID+"/Call/"+uuid+"/Play/", nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, nil)
+	resp, err := c.client.Do(ctx, req, nil)
 	return resp, err
 }
 
@@ -253,7 +406,7 @@ type CallSpeakParams struct {
 	Text     string `json:"text"`
 	Voice    string `json:"length,omitempty"`
 	Language string `json:"language,omitempty"`
-	Legs     string `json:"legs,omitempty"`
+	Legs     Legs   `json:"legs,omitempty"`
 	Loop     bool   `json:"loop,omitempty"`
 	Mix      bool   `json:"mix,omitempty"`
 }
@@ -264,31 +417,31 @@ type CallSpeakResponseBody struct {
 }
 
 // Speak plays text during a call (text to speech).
-func (c *CallService) Speak(uuid string, cp *CallSpeakParams) (*Response, error) {
+func (c *CallService) Speak(ctx context.Context, uuid string, cp *CallSpeakParams) (*Response, error) {
 	req, err := c.client.NewRequest("POST", c.client.authID+"/Call/"+uuid+"/Speak/", cp)
 	if err != nil {
 		return nil, err
 	}
 	aResp := &CallSpeakResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, aResp)
+	resp, err := c.client.Do(ctx, req, aResp)
 	return resp, err
 }
 
 // StopSpeaking stops playing text during a call.
-func (c *CallService) StopSpeaking(uuid string) (*Response, error) {
+func (c *CallService) StopSpeaking(ctx context.Context, uuid string) (*Response, error) {
 	req, err := c.client.NewRequest("DELETE", c.client.authID+"/Call/"+uuid+"/Speak/", nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, nil)
+	resp, err := c.client.Do(ctx, req, nil)
 	return resp, err
 }
 
 type CallDTMFParams struct {
 	Digits string `json:"digits"`
-	Legs   string `json:"legs,omitempty"`
+	Legs   Legs   `json:"legs,omitempty"`
 }
 
 type CallDTMFResponseBody struct {
@@ -297,24 +450,24 @@ type CallDTMFResponseBody struct {
 }
 
 // DTMF send digits on a call.
-func (c *CallService) DTMF(uuid string, cp *CallDTMFParams) (*Response, error) {
+func (c *CallService) DTMF(ctx context.Context, uuid string, cp *CallDTMFParams) (*Response, error) {
 	req, err := c.client.NewRequest("POST", c.client.authID+"/Call/"+uuid+"/DTMF/", cp)
 	if err != nil {
 		return nil, err
 	}
 	aResp := &CallDTMFResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, aResp)
+	resp, err := c.client.Do(ctx, req, aResp)
 	return resp, err
 }
 
 // Cancel hangups a call request.
-func (c *CallService) Cancel(request_uuid string) (*Response, error) {
+func (c *CallService) Cancel(ctx context.Context, request_uuid string) (*Response, error) {
 	req, err := c.client.NewRequest("DELETE", c.client.authID+"/Request/"+request_uuid, nil)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, nil)
+	resp, err := c.client.Do(ctx, req, nil)
 	return resp, err
 }
@@ -0,0 +1,196 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// CallEvent holds the parameters Plivo sends to every voice callback URL.
+type CallEvent struct {
+	CallUUID        string      `url:"CallUUID"`
+	RequestUUID     string      `url:"RequestUUID"`
+	From            PhoneNumber `url:"From"`
+	To              PhoneNumber `url:"To"`
+	CallerName      string      `url:"CallerName"`
+	Direction       string      `url:"Direction"`
+	CallStatus      string      `url:"CallStatus"`
+	Event           string      `url:"Event"`
+	ALegUUID        string      `url:"ALegUUID"`
+	ALegRequestUUID string      `url:"ALegRequestUUID"`
+
+	// SIPHeaders holds the custom X-PH- headers of the call.
+	SIPHeaders SIPHeaders `url:"-"`
+}
+
+// AnswerCallback is posted to CallMakeParams.AnswerURL when a call is answered.
+type AnswerCallback struct {
+	CallEvent
+
+	// Machine is set when machine detection is enabled and a machine answered.
+	Machine bool `url:"Machine"`
+}
+
+// RingCallback is posted to CallMakeParams.RingURL when the called party starts ringing.
+type RingCallback struct {
+	CallEvent
+}
+
+// HangupCallback is posted to CallMakeParams.HangupURL when a call ends.
+type HangupCallback struct {
+	CallEvent
+
+	HangupCause  string `url:"HangupCause"`
+	Duration     int64  `url:"Duration"`
+	BillDuration int64  `url:"BillDuration"`
+	BillRate     Money  `url:"BillRate"`
+	TotalCost    Money  `url:"TotalCost"`
+	StartTime    Time   `url:"StartTime"`
+	AnswerTime   Time   `url:"AnswerTime"`
+	EndTime      Time   `url:"EndTime"`
+	Machine      bool   `url:"Machine"`
+}
+
+// FallbackCallback is posted to CallMakeParams.FallbackURL when the answer URL fails.
+type FallbackCallback struct {
+	CallEvent
+}
+
+// MachineCallback is posted to the machine detection URL with the detection result.
+type MachineCallback struct {
+	CallEvent
+
+	Machine bool `url:"Machine"`
+}
+
+// ParseCallback decodes the form or JSON body of a callback request into v,
+// which must be a pointer to a struct whose fields carry `url` tags naming
+// the Plivo parameters, such as *AnswerCallback or *HangupCallback.
+func ParseCallback(r *http.Request, v interface{}) error {
+	params, err := callbackParams(r)
+	if err != nil {
+		return err
+	}
+	return decodeParams(params, v)
+}
+
+// callbackParams returns the parameters of a callback request, whatever its encoding.
+func callbackParams(r *http.Request) (url.Values, error) {
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if ct != "application/json" {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+		return r.Form, nil
+	}
+
+	var m map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		return nil, err
+	}
+	params := r.URL.Query()
+	for k, v := range m {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			params.Set(k, v)
+		case float64:
+			params.Set(k, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			params.Set(k, fmt.Sprint(v))
+		}
+	}
+	return params, nil
+}
+
+// decodeParams sets the `url`-tagged fields of the struct pointed to by v from params.
+func decodeParams(params url.Values, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("plivo: callback target must be a pointer to a struct")
+	}
+	return decodeStruct(params, rv.Elem())
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// valuesDecoder is implemented by field types decoded from all the
+// parameters at once rather than from a single one.
+type valuesDecoder interface {
+	decodeValues(params url.Values) error
+}
+
+func decodeStruct(params url.Values, sv reflect.Value) error {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		f, fv := st.Field(i), sv.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		if d, ok := fv.Addr().Interface().(valuesDecoder); ok {
+			if err := d.decodeValues(params); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := decodeStruct(params, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		name := strings.Split(f.Tag.Get("url"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		s, ok := params[name]
+		if !ok || len(s) == 0 || s[0] == "" {
+			continue
+		}
+		if err := setField(fv, s[0]); err != nil {
+			return fmt.Errorf("plivo: decoding %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func setField(fv reflect.Value, s string) error {
+	if fv.CanAddr() && fv.Addr().Type().Implements(textUnmarshalerType) {
+		return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported field type %v", fv.Type())
+	}
+	return nil
+}
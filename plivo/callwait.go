@@ -0,0 +1,168 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// CallState is a stage in the life of a call.
+type CallState string
+
+const (
+	CallQueued     CallState = "queued"
+	CallRinging    CallState = "ringing"
+	CallInProgress CallState = "in-progress"
+	CallCompleted  CallState = "completed"
+	CallBusy       CallState = "busy"
+	CallNoAnswer   CallState = "no-answer"
+	CallCancelled  CallState = "cancelled"
+	CallMachine    CallState = "machine"
+	CallFailed     CallState = "failed"
+)
+
+// Terminal reports whether a call in state s has ended.
+func (s CallState) Terminal() bool {
+	switch s {
+	case CallQueued, CallRinging, CallInProgress:
+		return false
+	}
+	return true
+}
+
+// Ways a call can end without being answered by a person. A *CallError
+// matches the one for its state with errors.Is.
+var (
+	ErrCallBusy      = errors.New("plivo: call busy")
+	ErrCallNoAnswer  = errors.New("plivo: call not answered")
+	ErrCallCancelled = errors.New("plivo: call cancelled")
+	ErrCallMachine   = errors.New("plivo: call answered by machine")
+	ErrCallFailed    = errors.New("plivo: call failed")
+)
+
+var callStateErrors = map[CallState]error{
+	CallBusy:      ErrCallBusy,
+	CallNoAnswer:  ErrCallNoAnswer,
+	CallCancelled: ErrCallCancelled,
+	CallMachine:   ErrCallMachine,
+	CallFailed:    ErrCallFailed,
+}
+
+// CallError reports a call that ended in a failed state.
+type CallError struct {
+	State CallState
+	Call  *Call // The call detail record.
+}
+
+func (e *CallError) Error() string {
+	return "plivo: call " + e.Call.CallUUID + " ended: " + string(e.State)
+}
+
+// Is reports whether target is the sentinel error for e.State, such as ErrCallBusy.
+func (e *CallError) Is(target error) bool {
+	return callStateErrors[e.State] == target
+}
+
+// hangupCauseStates maps Plivo's documented hangup cause names, in lower
+// case, to the states calls ending with them are in.
+var hangupCauseStates = map[string]CallState{
+	"normal hangup":               CallCompleted,
+	"end of xml instructions":     CallCompleted,
+	"busy line":                   CallBusy,
+	"no answer":                   CallNoAnswer,
+	"ring timeout reached":        CallNoAnswer,
+	"cancelled":                   CallCancelled,
+	"machine detected":            CallMachine,
+	"rejected":                    CallFailed,
+	"cancelled (out of credits)":  CallFailed,
+	"invalid destination address": CallFailed,
+	"unknown":                     CallFailed,
+}
+
+// State returns the final state of a call from the hangup cause of its detail
+// record. A call with a cause missing from Plivo's documented list is taken
+// to have completed if it was answered and to have failed otherwise.
+func (c *Call) State() CallState {
+	if st, ok := hangupCauseStates[strings.ToLower(strings.TrimSpace(c.HangupCause))]; ok {
+		return st
+	}
+	if c.BillDuration > 0 || !c.AnswerTime.IsZero() {
+		return CallCompleted
+	}
+	return CallFailed
+}
+
+// Polling intervals of WaitForCall.
+var (
+	callPollMin = 500 * time.Millisecond
+	callPollMax = 5 * time.Second
+)
+
+// WaitForCall polls a call made with Make until it reaches one of states or
+// ends, backing off between polls. For calls made through the API the call
+// UUID is the request UUID returned by Make.
+//
+// With no states it waits for the call to end. A live call reaching one of
+// states is returned as a Call holding the live details. A call that ends
+// returns its detail record, along with a *CallError if it was busy, not
+// answered, cancelled, answered by a machine or failed.
+func (s *CallService) WaitForCall(ctx context.Context, requestUUID string, states ...CallState) (*Call, error) {
+	want := map[CallState]bool{}
+	for _, st := range states {
+		want[st] = true
+	}
+
+	d := callPollMin
+	for {
+		lc, _, err := s.GetLive(ctx, requestUUID)
+		switch {
+		case err == nil:
+			st := lc.CallStatus
+			if st == "" {
+				st = CallInProgress
+			}
+			if want[st] {
+				return lc.call(), nil
+			}
+		case IsNotFound(err):
+			c, _, err := s.Get(ctx, requestUUID)
+			if err == nil {
+				if st := c.State(); st != CallCompleted && !want[st] {
+					return c, &CallError{State: st, Call: c}
+				}
+				return c, nil
+			}
+			if !IsNotFound(err) {
+				return nil, err
+			}
+			// Neither live nor ended yet: the call is still queued.
+			if want[CallQueued] {
+				return &Call{CallUUID: requestUUID}, nil
+			}
+		default:
+			return nil, err
+		}
+
+		if err := sleep(ctx, d); err != nil {
+			return nil, err
+		}
+		if d *= 2; d > callPollMax {
+			d = callPollMax
+		}
+	}
+}
+
+// call returns the details of a live call as a Call.
+func (lc *LiveCall) call() *Call {
+	return &Call{
+		FromNumber:     lc.From,
+		ToNumber:       lc.To,
+		AnswerURL:      lc.AnswerURL,
+		CallUUID:       lc.CallUUID,
+		ParentCallUUID: lc.ParentCallUUID,
+	}
+}
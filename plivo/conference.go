@@ -3,6 +3,8 @@
 
 package plivo
 
+import "context"
+
 type ConferenceService struct {
 	client *Client
 }
@@ -15,15 +17,15 @@ type Conference struct {
 }
 
 type Member struct {
-	Muted      bool   `json:"muted,omitempty"`
-	MemberID   string `json:"member_id,omitempty"`
-	Deaf       bool   `json:"deaf,omitempty"`
-	From       string `json:"from,omitempty"`
-	To         string `json:"to,omitempty"`
-	CallerName string `json:"caller_name,omitempty"`
-	Direction  string `json:"direction,omitempty"`
-	CallUUID   string `json:"call_uuid,omitempty"`
-	JoinTime   string `json:"join_time,omitempty"`
+	Muted      bool        `json:"muted,omitempty"`
+	MemberID   string      `json:"member_id,omitempty"`
+	Deaf       bool        `json:"deaf,omitempty"`
+	From       PhoneNumber `json:"from,omitempty"`
+	To         PhoneNumber `json:"to,omitempty"`
+	CallerName string      `json:"caller_name,omitempty"`
+	Direction  string      `json:"direction,omitempty"`
+	CallUUID   string      `json:"call_uuid,omitempty"`
+	JoinTime   Time        `json:"join_time,omitzero"`
 }
 
 type ConferenceGetAllAllResponseBody struct {
@@ -32,106 +34,106 @@ type ConferenceGetAllAllResponseBody struct {
 }
 
 // GetAll retrieves list of all conferences.
-func (s *ConferenceService) GetAll() ([]string, *Response, error) {
+func (s *ConferenceService) GetAll(ctx context.Context) ([]string, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Conference/", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &ConferenceGetAllAllResponseBody{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return aResp.Conferences, resp, err
 }
 
 // Get retrieves details of a particular conference.
-func (s *ConferenceService) Get(name string) (*Conference, *Response, error) {
+func (s *ConferenceService) Get(ctx context.Context, name string) (*Conference, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Conference/"+name+"/", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &Conference{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return aResp, resp, err
 }
 
 // HangupAll hangs up all conferences.
-func (s *ConferenceService) HangupAll() (*Response, error) {
+func (s *ConferenceService) HangupAll(ctx context.Context) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/Conference/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
 // Hangup hangs up a particular conference.
-func (s *ConferenceService) Hangup(name string) (*Response, error) {
+func (s *ConferenceService) Hangup(ctx context.Context, name string) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/Conference/"+name+"/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
 // HangupMember hangs up member(s).
-func (s *ConferenceService) HangupMember(name, member string) (*Response, error) {
+func (s *ConferenceService) HangupMember(ctx context.Context, name, member string) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/Conference/"+name+"/Member/"+member+"/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
 // KickMembers kicks member(s).
-func (s *ConferenceService) KickMembers(name, members string) (*Response, error) {
+func (s *ConferenceService) KickMembers(ctx context.Context, name, members string) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/Conference/"+name+"/Member/"+members+"/Kick/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
 // MuteMembers mutes member(s).
-func (s *ConferenceService) MuteMembers(name, members string) (*Response, error) {
+func (s *ConferenceService) MuteMembers(ctx context.Context, name, members string) (*Response, error) {
 	req, err := s.client.NewRequest("POST", s.client.authID+"/Conference/"+name+"/Member/"+members+"/Mute/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
 // UnmuteMembers unmutes member(s).
-func (s *ConferenceService) UnmuteMembers(name, members string) (*Response, error) {
+func (s *ConferenceService) UnmuteMembers(ctx context.Context, name, members string) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/Conference/"+name+"/Member/"+members+"/Mute/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
 // Play starts playing sound to member(s).
-func (s *ConferenceService) Play(name, members, url string) (*Response, error) {
+func (s *ConferenceService) Play(ctx context.Context, name, members, url string) (*Response, error) {
 	rp := struct{ URL string }{url}
 	req, err := s.client.NewRequest("POST", s.client.authID+"/Conference/"+name+"/Member/"+members+"/Play/", rp)
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
 // StopPlaying stops playing sound to member(s).
-func (s *ConferenceService) StopPlaying(name, members string) (*Response, error) {
+func (s *ConferenceService) StopPlaying(ctx context.Context, name, members string) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/Conference/"+name+"/Member/"+members+"/Play/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
@@ -147,34 +149,34 @@ type ConferenceSpeakResponseBody struct {
 }
 
 // Speak makes member(s) listen to a speech.
-func (c *ConferenceService) Speak(name, members string, cp *ConferenceSpeakParams) (*Response, error) {
+func (c *ConferenceService) Speak(ctx context.Context, name, members string, cp *ConferenceSpeakParams) (*Response, error) {
 	req, err := c.client.NewRequest("POST", c.client.authID+"/Conference/"+name+"/Member/"+members+"/Speak/", cp)
 	if err != nil {
 		return nil, err
 	}
 	aResp := &ConferenceSpeakResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, aResp)
+	resp, err := c.client.Do(ctx, req, aResp)
 	return resp, err
 }
 
 // DisableHearingMembers makes member(s) deaf.
-func (s *ConferenceService) DisableHearingMembers(name, members string) (*Response, error) {
+func (s *ConferenceService) DisableHearingMembers(ctx context.Context, name, members string) (*Response, error) {
 	req, err := s.client.NewRequest("POST", s.client.authID+"/Conference/"+name+"/Member/"+members+"/Deaf/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
-//EnableHearingMembers enables hearing for member(s).
-func (s *ConferenceService) EnableHearingMembers(name, members string) (*Response, error) {
+// EnableHearingMembers enables hearing for member(s).
+func (s *ConferenceService) EnableHearingMembers(ctx context.Context, name, members string) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/Conference/"+name+"/Member/"+members+"/Deaf/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
@@ -194,23 +196,23 @@ type ConferenceRecordResponseBody struct {
 }
 
 // Record records a conference.
-func (c *ConferenceService) Record(id string, cp *ConferenceRecordParams) (*Response, error) {
+func (c *ConferenceService) Record(ctx context.Context, id string, cp *ConferenceRecordParams) (*Response, error) {
 	req, err := c.client.NewRequest("POST", c.client.authID+"/Conference/"+id+"/Record/", cp)
 	if err != nil {
 		return nil, err
 	}
 	aResp := &ConferenceRecordResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, aResp)
+	resp, err := c.client.Do(ctx, req, aResp)
 	return resp, err
 }
 
 // StopRecording cancels a conference recording.
-func (c *ConferenceService) StopRecording(id string) (*Response, error) {
+func (c *ConferenceService) StopRecording(ctx context.Context, id string) (*Response, error) {
 	req, err := c.client.NewRequest("DELETE", c.client.authID+"/Conference/"+id+"/Record/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.client.Do(req, nil)
+	resp, err := c.client.Do(ctx, req, nil)
 	return resp, err
 }
@@ -0,0 +1,229 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// MessageStatus is the delivery status of a message.
+type MessageStatus string
+
+const (
+	MessageQueued      MessageStatus = "queued"
+	MessageSent        MessageStatus = "sent"
+	MessageDelivered   MessageStatus = "delivered"
+	MessageUndelivered MessageStatus = "undelivered"
+	MessageFailed      MessageStatus = "failed"
+	MessageRejected    MessageStatus = "rejected"
+)
+
+// Terminal reports whether no further status follows s.
+func (s MessageStatus) Terminal() bool {
+	switch s {
+	case MessageDelivered, MessageUndelivered, MessageFailed, MessageRejected:
+		return true
+	}
+	return false
+}
+
+// DeliveryReport is posted to MessageSendParams.URL as the status of a
+// message changes.
+type DeliveryReport struct {
+	MessageUUID       string        `url:"MessageUUID"`
+	ParentMessageUUID string        `url:"ParentMessageUUID"`
+	PartInfo          string        `url:"PartInfo"`
+	From              PhoneNumber   `url:"From"`
+	To                PhoneNumber   `url:"To"`
+	Status            MessageStatus `url:"Status"`
+	// ErrorCode explains an undelivered, failed or rejected status.
+	ErrorCode   string `url:"ErrorCode"`
+	Units       int64  `url:"Units"`
+	TotalRate   Money  `url:"TotalRate"`
+	TotalAmount Money  `url:"TotalAmount"`
+	MCC         string `url:"MCC"`
+	MNC         string `url:"MNC"`
+}
+
+// DeliveryStore keeps the latest delivery report of each message.
+// Implementations must be safe for concurrent use.
+type DeliveryStore interface {
+	// Put records r as the latest report of r.MessageUUID.
+	Put(ctx context.Context, r *DeliveryReport) error
+	// Get returns the latest report of a message, or nil if there is none.
+	Get(ctx context.Context, messageUUID string) (*DeliveryReport, error)
+}
+
+// MemoryDeliveryStore is a DeliveryStore held in memory.
+type MemoryDeliveryStore struct {
+	mu      sync.Mutex
+	reports map[string]*DeliveryReport
+}
+
+// NewMemoryDeliveryStore returns an empty MemoryDeliveryStore.
+func NewMemoryDeliveryStore() *MemoryDeliveryStore {
+	return &MemoryDeliveryStore{reports: make(map[string]*DeliveryReport)}
+}
+
+// Put implements DeliveryStore.
+func (s *MemoryDeliveryStore) Put(ctx context.Context, r *DeliveryReport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reports[r.MessageUUID] = r
+	return nil
+}
+
+// Get implements DeliveryStore.
+func (s *MemoryDeliveryStore) Get(ctx context.Context, messageUUID string) (*DeliveryReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reports[messageUUID], nil
+}
+
+// DeliveryHandler receives delivery reports at the URL given in
+// MessageSendParams.URL and records them in its store. A report arriving
+// after a terminal one for the same message, out of order, is ignored.
+type DeliveryHandler struct {
+	// Validator, if set, rejects requests without a valid signature with
+	// 403 Forbidden.
+	Validator *SignatureValidator
+	// Store holds the latest report of each message. If nil, reports are
+	// only passed to OnReport.
+	Store DeliveryStore
+	// OnReport, if set, is called with each report recorded.
+	OnReport func(*DeliveryReport)
+
+	recording sync.Mutex // serialises updates of the store
+
+	mu      sync.Mutex
+	waiters map[string]*deliveryWaiter
+}
+
+// deliveryWaiter is a channel closed on the next report of a message, shared
+// by the n calls of Wait for it.
+type deliveryWaiter struct {
+	ch chan struct{}
+	n  int
+}
+
+// NewDeliveryHandler returns a DeliveryHandler checking signatures with v,
+// if not nil, and recording reports in store, or in a new
+// MemoryDeliveryStore if store is nil.
+func NewDeliveryHandler(v *SignatureValidator, store DeliveryStore) *DeliveryHandler {
+	if store == nil {
+		store = NewMemoryDeliveryStore()
+	}
+	return &DeliveryHandler{Validator: v, Store: store}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *DeliveryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.Validator != nil {
+		if err := h.Validator.Validate(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	rep := &DeliveryReport{}
+	if err := ParseCallback(r, rep); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if rep.MessageUUID == "" {
+		http.Error(w, "plivo: delivery report without MessageUUID", http.StatusBadRequest)
+		return
+	}
+	if err := h.record(r.Context(), rep); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// record stores rep unless a terminal report of the message is already stored.
+func (h *DeliveryHandler) record(ctx context.Context, rep *DeliveryReport) error {
+	if h.Store != nil {
+		h.recording.Lock()
+		defer h.recording.Unlock()
+		prev, err := h.Store.Get(ctx, rep.MessageUUID)
+		if err != nil {
+			return err
+		}
+		if prev != nil && prev.Status.Terminal() && !rep.Status.Terminal() {
+			return nil
+		}
+		if err := h.Store.Put(ctx, rep); err != nil {
+			return err
+		}
+	}
+	if h.OnReport != nil {
+		h.OnReport(rep)
+	}
+
+	h.mu.Lock()
+	if w, ok := h.waiters[rep.MessageUUID]; ok {
+		close(w.ch)
+		delete(h.waiters, rep.MessageUUID)
+	}
+	h.mu.Unlock()
+	return nil
+}
+
+// changed returns a waiter whose channel is closed when the next report of
+// messageUUID is recorded. It must be given back to release.
+func (h *DeliveryHandler) changed(messageUUID string) *deliveryWaiter {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.waiters == nil {
+		h.waiters = make(map[string]*deliveryWaiter)
+	}
+	w, ok := h.waiters[messageUUID]
+	if !ok {
+		w = &deliveryWaiter{ch: make(chan struct{})}
+		h.waiters[messageUUID] = w
+	}
+	w.n++
+	return w
+}
+
+// release gives back w, forgetting it once no Wait uses it.
+func (h *DeliveryHandler) release(messageUUID string, w *deliveryWaiter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	w.n--
+	if w.n == 0 && h.waiters[messageUUID] == w {
+		delete(h.waiters, messageUUID)
+	}
+}
+
+// Wait blocks until the message reaches a terminal status, as received by
+// this handler, and returns its report. If ctx is done first, it returns the
+// latest report, if any, with the context's error.
+func (h *DeliveryHandler) Wait(ctx context.Context, messageUUID string) (*DeliveryReport, error) {
+	if h.Store == nil {
+		return nil, errors.New("plivo: delivery handler has no store")
+	}
+	for {
+		w := h.changed(messageUUID)
+		rep, err := h.Store.Get(ctx, messageUUID)
+		if err != nil {
+			h.release(messageUUID, w)
+			return nil, err
+		}
+		if rep != nil && rep.Status.Terminal() {
+			h.release(messageUUID, w)
+			return rep, nil
+		}
+		select {
+		case <-w.ch:
+			h.release(messageUUID, w)
+		case <-ctx.Done():
+			h.release(messageUUID, w)
+			return rep, ctx.Err()
+		}
+	}
+}
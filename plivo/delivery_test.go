@@ -0,0 +1,123 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeliveryHandler(t *testing.T) {
+	const token = "token"
+	h := NewDeliveryHandler(NewSignatureValidator(token), nil)
+	var reported []MessageStatus
+	h.OnReport = func(r *DeliveryReport) { reported = append(reported, r.Status) }
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	nonce := 0
+	post := func(form url.Values, signed bool) int {
+		req, _ := http.NewRequest("POST", server.URL+"/dlr", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if signed {
+			nonce++
+			n := fmt.Sprint(nonce)
+			req.Header.Set("X-Plivo-Signature-V3", signatureV3(token, "POST", server.URL+"/dlr", n, form))
+			req.Header.Set("X-Plivo-Signature-V3-Nonce", n)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	report := func(status MessageStatus) url.Values {
+		return url.Values{"MessageUUID": {"m1"}, "To": {"14155550101"}, "Status": {string(status)}, "Units": {"2"}, "TotalRate": {"0.0035"}}
+	}
+
+	if code := post(report(MessageDelivered), false); code != http.StatusForbidden {
+		t.Errorf("unsigned report got %d, want 403", code)
+	}
+	if code := post(url.Values{"Status": {"sent"}}, true); code != http.StatusBadRequest {
+		t.Errorf("report without MessageUUID got %d, want 400", code)
+	}
+	if code := post(report(MessageSent), true); code != http.StatusOK {
+		t.Errorf("report got %d", code)
+	}
+
+	wctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	rep, err := h.Wait(wctx, "m1")
+	cancel()
+	if err != context.DeadlineExceeded || rep == nil || rep.Status != MessageSent {
+		t.Errorf("Wait before delivery = %+v, %v", rep, err)
+	}
+
+	done := make(chan *DeliveryReport)
+	go func() {
+		rep, err := h.Wait(ctx, "m1")
+		if err != nil {
+			t.Errorf("Wait failed: %v", err)
+		}
+		done <- rep
+	}()
+	time.Sleep(5 * time.Millisecond)
+	post(report(MessageDelivered), true)
+	rep = <-done
+	if rep.Status != MessageDelivered || rep.Units != 2 || rep.TotalRate.String() != "0.0035" {
+		t.Errorf("Wait = %+v", rep)
+	}
+
+	// A late non-terminal report does not replace the delivered one.
+	post(report(MessageQueued), true)
+	if rep, _ := h.Store.Get(ctx, "m1"); rep.Status != MessageDelivered {
+		t.Errorf("stored status = %s, want delivered", rep.Status)
+	}
+	if fmt.Sprint(reported) != "[sent delivered]" {
+		t.Errorf("OnReport saw %v", reported)
+	}
+}
+
+func TestDeliveryHandlerWaiters(t *testing.T) {
+	h := NewDeliveryHandler(nil, nil)
+	for i := 0; i < 3; i++ {
+		wctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+		h.Wait(wctx, fmt.Sprint("m", i))
+		cancel()
+	}
+	if len(h.waiters) != 0 {
+		t.Errorf("%d waiters left after Wait returned", len(h.waiters))
+	}
+
+	// A Wait giving up leaves the channel to the others for the same message.
+	done := make(chan *DeliveryReport)
+	go func() {
+		rep, _ := h.Wait(ctx, "m1")
+		done <- rep
+	}()
+	time.Sleep(5 * time.Millisecond)
+	wctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	h.Wait(wctx, "m1")
+	cancel()
+	h.record(ctx, &DeliveryReport{MessageUUID: "m1", Status: MessageRejected})
+	select {
+	case rep := <-done:
+		if rep.Status != MessageRejected {
+			t.Errorf("Wait = %+v, want rejected", rep)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Wait did not return on a rejected report")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.waiters) != 0 {
+		t.Errorf("%d waiters left after the message was rejected", len(h.waiters))
+	}
+}
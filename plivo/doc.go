@@ -10,12 +10,20 @@ A short example of retrieving account details:
 
   func main() {
     client := plivo.NewClient(nil, authID, authToken)
-    acc, _, err := client.Account.Get()
+    acc, _, err := client.Account.Get(context.Background())
     if err != nil {
       log.Fatalf("AccountGet failed: %v", err)
     } else {
       log.Printf("Account: %v\n", acc)
     }
   }
+
+Every service method takes a context.Context as its first argument. It is
+attached to the underlying HTTP request, so cancellation, deadlines and
+request-scoped values propagate to the API call:
+
+  ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+  defer cancel()
+  calls, _, err := client.Call.GetAll(ctx, &plivo.CallGetAllParams{})
 */
 package plivo
@@ -3,6 +3,8 @@
 
 package plivo
 
+import "context"
+
 type EndpointService struct {
 	client *Client
 }
@@ -25,7 +27,7 @@ type EndpointsResponseBody struct {
 }
 
 // GetEndpoints retrieves a list of all endpoints.
-func (s *EndpointService) GetEndpoints(limit, offset int64) ([]*Endpoint, *Response, error) {
+func (s *EndpointService) GetEndpoints(ctx context.Context, limit, offset int64) ([]*Endpoint, *Response, error) {
 	limitOffset := &limitOffset{limit, offset}
 
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Endpoint/", limitOffset)
@@ -35,11 +37,21 @@ func (s *EndpointService) GetEndpoints(limit, offset int64) ([]*Endpoint, *Respo
 	}
 
 	aResp := &EndpointsResponseBody{}
-	resp, err := s.client.Do(req, aResp)
-	resp.Meta = aResp.Meta
+	resp, err := s.client.Do(ctx, req, aResp)
+	if resp != nil {
+		resp.Meta = aResp.Meta
+	}
 	return aResp.Objects, resp, err
 }
 
+// GetEndpointsPager returns a Pager which walks all endpoints, page by page.
+func (s *EndpointService) GetEndpointsPager(ctx context.Context) *Pager[*Endpoint] {
+	return NewPager(ctx, 0, func(ctx context.Context, limit, offset int64) ([]*Endpoint, *Meta, error) {
+		objs, resp, err := s.GetEndpoints(ctx, limit, offset)
+		return objs, pageMeta(resp), err
+	})
+}
+
 // Stores response for Create call
 type EndpointCreateResponseBody struct {
 	Message string `json:"message"`
@@ -47,47 +59,47 @@ type EndpointCreateResponseBody struct {
 }
 
 // Create creates an endpoint.
-func (s *EndpointService) Create(ep *Endpoint) (*Endpoint, *Response, error) {
+func (s *EndpointService) Create(ctx context.Context, ep *Endpoint) (*Endpoint, *Response, error) {
 	req, err := s.client.NewRequest("POST", s.client.authID+"/Endpoint/", ep)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &EndpointCreateResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return ep, resp, err
 }
 
 // Get fetches a particular endpoint.
-func (s *EndpointService) Get(id string) (*Endpoint, *Response, error) {
+func (s *EndpointService) Get(ctx context.Context, id string) (*Endpoint, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Endpoint/"+id+"/", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &Endpoint{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return aResp, resp, err
 }
 
 // Modify edits an endpoint.
-func (s *EndpointService) Modify(ep *Endpoint) (*Endpoint, *Response, error) {
+func (s *EndpointService) Modify(ctx context.Context, ep *Endpoint) (*Endpoint, *Response, error) {
 	req, err := s.client.NewRequest("POST", s.client.authID+"/Endpoint/"+ep.EndpointID+"/", ep)
 	if err != nil {
 		return nil, nil, err
 	}
 	req.Header.Add("Content-Type", "application/json")
 	aResp := &ModifyResponseBody{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 
 	return ep, resp, err
 }
 
 // Delete deletes an endpoint.
-func (s *EndpointService) Delete(id string) (*Response, error) {
+func (s *EndpointService) Delete(ctx context.Context, id string) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/Endpoint/"+id+"/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
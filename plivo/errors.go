@@ -0,0 +1,66 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Classes of API errors. An *ErrorResponse matches one of them with errors.Is
+// according to its HTTP status code. ErrRateLimited is also returned by the
+// client-side rate limiters.
+var (
+	ErrNotFound     = errors.New("plivo: resource not found")
+	ErrUnauthorized = errors.New("plivo: unauthorized")
+	ErrValidation   = errors.New("plivo: invalid request")
+	ErrServerError  = errors.New("plivo: server error")
+)
+
+// Is reports whether the error belongs to the class target, so that
+// errors.Is(err, ErrNotFound) works on errors returned by any service.
+func (r *ErrorResponse) Is(target error) bool {
+	if r.Response == nil {
+		return false
+	}
+	switch c := r.Response.StatusCode; target {
+	case ErrNotFound:
+		return c == http.StatusNotFound
+	case ErrUnauthorized:
+		return c == http.StatusUnauthorized || c == http.StatusForbidden
+	case ErrRateLimited:
+		return c == http.StatusTooManyRequests
+	case ErrValidation:
+		return c == http.StatusBadRequest || c == http.StatusUnprocessableEntity
+	case ErrServerError:
+		return 500 <= c && c <= 599
+	}
+	return false
+}
+
+// IsNotFound reports whether err is an API error for a missing resource.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsUnauthorized reports whether err is an API error for bad credentials or permissions.
+func IsUnauthorized(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsRateLimited reports whether err comes from the API or a client-side rate limiter
+// rejecting a request for exceeding the allowed rate.
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsValidation reports whether err is an API error for invalid parameters.
+func IsValidation(err error) bool {
+	return errors.Is(err, ErrValidation)
+}
+
+// IsServerError reports whether err is an API error caused by the server.
+func IsServerError(err error) bool {
+	return errors.Is(err, ErrServerError)
+}
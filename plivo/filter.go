@@ -0,0 +1,70 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Op is a comparison operator understood by the list endpoints. It is
+// appended to the parameter name, as in end_time__gte.
+type Op string
+
+const (
+	Eq  Op = ""
+	Gt  Op = "gt"
+	Gte Op = "gte"
+	Lt  Op = "lt"
+	Lte Op = "lte"
+)
+
+// filterTimeLayout is the layout of time filters. The API interprets them as UTC.
+const filterTimeLayout = "2006-01-02 15:04:05"
+
+// key returns the query parameter name for the operator applied to name.
+func (op Op) key(name string) string {
+	if op == Eq {
+		return name
+	}
+	return name + "__" + string(op)
+}
+
+// TimeFilter compares a timestamp field, for example:
+//
+//	plivo.TimeFilter{plivo.Gte: start, plivo.Lt: end}
+type TimeFilter map[Op]time.Time
+
+// TimeBetween returns a filter matching times in [from, to).
+func TimeBetween(from, to time.Time) TimeFilter {
+	return TimeFilter{Gte: from, Lt: to}
+}
+
+// EncodeValues implements query.Encoder.
+func (f TimeFilter) EncodeValues(key string, v *url.Values) error {
+	for op, t := range f {
+		v.Set(op.key(key), t.UTC().Format(filterTimeLayout))
+	}
+	return nil
+}
+
+// IntFilter compares a numeric field, for example plivo.IntFilter{plivo.Gt: 60}.
+type IntFilter map[Op]int64
+
+// EncodeValues implements query.Encoder.
+func (f IntFilter) EncodeValues(key string, v *url.Values) error {
+	for op, n := range f {
+		v.Set(op.key(key), strconv.FormatInt(n, 10))
+	}
+	return nil
+}
+
+// Direction is the direction of a call or message.
+type Direction string
+
+const (
+	Inbound  Direction = "inbound"
+	Outbound Direction = "outbound"
+)
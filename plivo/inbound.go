@@ -0,0 +1,129 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/micrypt/go-plivo/plivoxml"
+)
+
+// InboundMessage is posted to Application.MessageURL when one of the
+// application's numbers receives a message.
+type InboundMessage struct {
+	MessageUUID string      `url:"MessageUUID"`
+	From        PhoneNumber `url:"From"`
+	To          PhoneNumber `url:"To"`
+	Text        string      `url:"Text"`
+	Type        string      `url:"Type"`
+	Units       int64       `url:"Units"`
+	TotalRate   Money       `url:"TotalRate"`
+	TotalAmount Money       `url:"TotalAmount"`
+}
+
+// Reply answers an inbound message, from the number it was sent to.
+type Reply struct {
+	Text string
+	// URL and Method, if set, receive the delivery reports of the reply.
+	URL    string
+	Method string
+	// Async sends the reply with MessageService.Send after Plivo's request
+	// has been answered, rather than as a <Message> in the response.
+	Async bool
+}
+
+// InboundFunc handles an inbound message and returns the reply to send, or
+// nil for none.
+type InboundFunc func(ctx context.Context, m *InboundMessage) (*Reply, error)
+
+// InboundHandler receives inbound messages at Application.MessageURL and
+// passes them to Handle. Its response is a Plivo XML document holding the
+// reply, if any.
+type InboundHandler struct {
+	// Validator, if set, rejects requests without a valid signature with
+	// 403 Forbidden.
+	Validator *SignatureValidator
+	Handle    InboundFunc
+	// Client sends asynchronous replies.
+	Client *Client
+	// ReplyTimeout limits the sending of each asynchronous reply. It
+	// defaults to 30 seconds.
+	ReplyTimeout time.Duration
+	// OnError, if set, is called with the errors of asynchronous replies.
+	OnError func(m *InboundMessage, err error)
+}
+
+// InboundHandler returns an InboundHandler passing messages to f, which
+// checks signatures with the client's auth token and sends asynchronous
+// replies through the client.
+func (c *Client) InboundHandler(f InboundFunc) *InboundHandler {
+	return &InboundHandler{Validator: c.SignatureValidator(), Handle: f, Client: c}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *InboundHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.Validator != nil {
+		if err := h.Validator.Validate(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	m := &InboundMessage{}
+	if err := ParseCallback(r, m); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reply, err := h.Handle(r.Context(), m)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := plivoxml.NewResponse()
+	switch {
+	case reply == nil:
+	case reply.Async:
+		if h.Client == nil {
+			http.Error(w, "plivo: inbound handler has no client for asynchronous replies", http.StatusInternalServerError)
+			return
+		}
+		go h.send(context.WithoutCancel(r.Context()), m, reply)
+	default:
+		resp.Add(&plivoxml.Message{
+			Src:            string(m.To),
+			Dst:            string(m.From),
+			Text:           reply.Text,
+			CallbackURL:    reply.URL,
+			CallbackMethod: reply.Method,
+		})
+	}
+	if err := resp.Write(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// defaultReplyTimeout is the ReplyTimeout of an InboundHandler which sets none.
+const defaultReplyTimeout = 30 * time.Second
+
+// send sends reply to m with the API, giving up after h.ReplyTimeout.
+func (h *InboundHandler) send(ctx context.Context, m *InboundMessage, reply *Reply) {
+	timeout := h.ReplyTimeout
+	if timeout <= 0 {
+		timeout = defaultReplyTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	_, _, err := h.Client.Message.Send(ctx, &MessageSendParams{
+		Src:    m.To,
+		Dst:    m.From,
+		Text:   reply.Text,
+		URL:    reply.URL,
+		Method: reply.Method,
+	})
+	if err != nil && h.OnError != nil {
+		h.OnError(m, err)
+	}
+}
@@ -0,0 +1,109 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInboundHandler(t *testing.T) {
+	sent := make(chan MessageSendParams, 1)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p MessageSendParams
+		json.NewDecoder(r.Body).Decode(&p)
+		fmt.Fprint(w, `{"message_uuid": ["m2"]}`)
+		sent <- p
+	}))
+	defer api.Close()
+	client := newTestClient(api)
+
+	h := client.InboundHandler(func(ctx context.Context, m *InboundMessage) (*Reply, error) {
+		switch m.Text {
+		case "hi":
+			return &Reply{Text: "Hello " + string(m.From)}, nil
+		case "later":
+			return &Reply{Text: "Done", Async: true}, nil
+		case "fail":
+			return nil, errors.New("boom")
+		}
+		return nil, nil
+	})
+	h.Validator = nil
+	receive := func(text string) *httptest.ResponseRecorder {
+		form := url.Values{"MessageUUID": {"m1"}, "From": {"14155550101"}, "To": {"14155550100"}, "Text": {text}, "Type": {"sms"}}
+		r := httptest.NewRequest("POST", "/message", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	w := receive("hi")
+	if want := `<Response><Message src="14155550100" dst="14155550101">Hello 14155550101</Message></Response>`; w.Body.String() != want || w.Header().Get("Content-Type") != "application/xml" {
+		t.Errorf("reply = %q, want %q", w.Body, want)
+	}
+	if w := receive("thanks"); w.Code != http.StatusOK || w.Body.String() != "<Response></Response>" {
+		t.Errorf("no reply = %d %q", w.Code, w.Body)
+	}
+	if w := receive("fail"); w.Code != http.StatusInternalServerError {
+		t.Errorf("failed handler = %d", w.Code)
+	}
+	if w := receive("later"); w.Body.String() != "<Response></Response>" {
+		t.Errorf("async reply = %q", w.Body)
+	}
+	select {
+	case p := <-sent:
+		if p.Src != "14155550100" || p.Dst != "14155550101" || p.Text != "Done" {
+			t.Errorf("async reply sent %+v", p)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("async reply not sent")
+	}
+
+	h = client.InboundHandler(func(context.Context, *InboundMessage) (*Reply, error) { return nil, nil })
+	if w := receive("hi"); w.Code != http.StatusForbidden {
+		t.Errorf("unsigned message = %d, want 403", w.Code)
+	}
+}
+
+func TestInboundHandlerReplyTimeout(t *testing.T) {
+	release := make(chan struct{})
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer api.Close()
+	defer close(release)
+	client := newTestClient(api)
+	client.RetryPolicy = nil
+
+	failed := make(chan error, 1)
+	h := client.InboundHandler(func(context.Context, *InboundMessage) (*Reply, error) {
+		return &Reply{Text: "Done", Async: true}, nil
+	})
+	h.Validator = nil
+	h.ReplyTimeout = 10 * time.Millisecond
+	h.OnError = func(m *InboundMessage, err error) { failed <- err }
+
+	form := url.Values{"MessageUUID": {"m1"}, "From": {"14155550101"}, "To": {"14155550100"}, "Text": {"later"}}
+	r := httptest.NewRequest("POST", "/message", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	select {
+	case err := <-failed:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("stuck reply failed with %v, want deadline exceeded", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("stuck reply not given up")
+	}
+}
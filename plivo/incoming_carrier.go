@@ -3,17 +3,19 @@
 
 package plivo
 
+import "context"
+
 type IncomingCarrierService struct {
 	client *Client
 }
 
 type IncomingCarrier struct {
-	CarrierID   string
-	IPSet       string
-	Name        string
-	ResourceURI string
-	SMS         bool
-	Voice       bool
+	CarrierID   string `json:"carrier_id,omitempty"`
+	IPSet       string `json:"ip_set,omitempty"`
+	Name        string `json:"name,omitempty"`
+	ResourceURI string `json:"resource_uri,omitempty"`
+	SMS         bool   `json:"sms,omitempty"`
+	Voice       bool   `json:"voice,omitempty"`
 }
 
 type IncomingCarrierGetAllResponseBody struct {
@@ -24,40 +26,43 @@ type IncomingCarrierGetAllResponseBody struct {
 
 type IncomingCarrierGetAllParams struct {
 	// Query parameters.
-	Name   string `json:"name,omitempty"`
-	Limit  int64  `json:"limit:omitempty"`
-	Offset int64  `json:"offset:omitempty"`
+	Name   string `url:"name,omitempty"`
+	Limit  int64  `url:"limit,omitempty"`
+	Offset int64  `url:"offset,omitempty"`
 }
 
-func (s *IncomingCarrierService) GetAll(p *IncomingCarrierGetAllParams) ([]*IncomingCarrier, *Response, error) {
+// GetAll fetches all incoming carriers.
+func (s *IncomingCarrierService) GetAll(ctx context.Context, p *IncomingCarrierGetAllParams) ([]*IncomingCarrier, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/IncomingCarrier/", p)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &IncomingCarrierGetAllResponseBody{}
-	resp, err := s.client.Do(req, aResp)
-	resp.Meta = aResp.Meta
+	resp, err := s.client.Do(ctx, req, aResp)
+	if resp != nil {
+		resp.Meta = aResp.Meta
+	}
 	return aResp.Objects, resp, err
 }
 
 // Get fetches a specified carrier.
-func (s *IncomingCarrierService) Get(carrierID string) (*IncomingCarrier, *Response, error) {
+func (s *IncomingCarrierService) Get(ctx context.Context, carrierID string) (*IncomingCarrier, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/IncomingCarrier/"+carrierID+"/", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &IncomingCarrier{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return aResp, resp, err
 }
 
 // Remove removes a carrier, and deletes all numbers associated with the carrier.
-func (s *CallService) Remove(carrierID string) (*Response, error) {
+func (s *IncomingCarrierService) Remove(ctx context.Context, carrierID string) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/IncomingCarrier/"+carrierID+"/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
@@ -73,14 +78,14 @@ type IncomingCarrierResponseBody struct {
 }
 
 // Add adds an incoming carrier.
-func (s *IncomingCarrierService) Add(p *IncomingCarrierAddParams) (*Response, error) {
+func (s *IncomingCarrierService) Add(ctx context.Context, p *IncomingCarrierAddParams) (*Response, error) {
 	req, err := s.client.NewRequest("POST", s.client.authID+"/IncomingCarrier/", p)
 	if err != nil {
 		return nil, err
 	}
 	aResp := &IncomingCarrierResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return resp, err
 }
 
@@ -90,13 +95,13 @@ type IncomingCarrierModifyParams struct {
 }
 
 // Modify updates an incoming carrier.
-func (s *IncomingCarrierService) Modify(p *IncomingCarrierModifyParams) (*Response, error) {
-	req, err := s.client.NewRequest("POST", s.client.authID+"/IncomingCarrier/", p)
+func (s *IncomingCarrierService) Modify(ctx context.Context, carrierID string, p *IncomingCarrierModifyParams) (*Response, error) {
+	req, err := s.client.NewRequest("POST", s.client.authID+"/IncomingCarrier/"+carrierID+"/", p)
 	if err != nil {
 		return nil, err
 	}
 	aResp := &IncomingCarrierResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return resp, err
 }
@@ -3,32 +3,47 @@
 
 package plivo
 
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
 type MessageService struct {
 	client *Client
 }
 
 type MessageSendParams struct {
-	Src  string `json:"src,omitempty"`
-	Dst  string `json:"dst,omitempty"`
-	Text string `json:"text,omitempty"`
+	// Src may also be an alphanumeric sender ID or a short code.
+	Src  PhoneNumber `json:"src,omitempty"`
+	Dst  PhoneNumber `json:"dst,omitempty"`
+	Text string      `json:"text,omitempty"`
 	// Optional parameters.
 	Type   string `json:"type,omitempty"`
 	URL    string `json:"url,omitempty"`
 	Method string `json:"method,omitempty"`
+
+	// Client-side options, not sent to the API.
+	// MaxSegments, if positive, makes Send fail with ErrTooManySegments
+	// rather than send Text as more segments.
+	MaxSegments int `json:"-"`
+	// Transliterate replaces smart punctuation in Text with GSM-7
+	// equivalents before it is sent.
+	Transliterate bool `json:"-"`
 }
 
 type Message struct {
-	ToNumber         string `json:"to_number,omitempty"`
-	FromNumber       string `json:"from_number,omitempty"`
-	CloudRate        string `json:"cloud_rate,omitempty"`
-	MessageType      string `json:"message_type,omitempty"`
-	ResourceURI      string `json:"resource_uri,omitempty"`
-	CarrierRate      string `json:"carrier_rate,omitempty"`
-	MessageDirection string `json:"message_direction,omitempty"`
-	MessageState     string `json:"message_state,omitempty"`
-	TotalAmount      string `json:"total_amount,omitempty"`
-	MessageUUID      string `json:"message_uuid,omitempty"`
-	MessageTime      string `json:"message_time,omitempty"`
+	ToNumber         PhoneNumber `json:"to_number,omitempty"`
+	FromNumber       PhoneNumber `json:"from_number,omitempty"`
+	CloudRate        Money       `json:"cloud_rate,omitzero"`
+	MessageType      string      `json:"message_type,omitempty"`
+	ResourceURI      string      `json:"resource_uri,omitempty"`
+	CarrierRate      Money       `json:"carrier_rate,omitzero"`
+	MessageDirection string      `json:"message_direction,omitempty"`
+	MessageState     string      `json:"message_state,omitempty"`
+	TotalAmount      Money       `json:"total_amount,omitzero"`
+	MessageUUID      string      `json:"message_uuid,omitempty"`
+	MessageTime      Time        `json:"message_time,omitzero"`
 }
 
 // Stores response for ending a message.
@@ -40,20 +55,41 @@ type MessageSendResponseBody struct {
 }
 
 // Make creates a call.
-func (c *MessageService) Send(mp *MessageSendParams) (*MessageSendResponseBody, *Response, error) {
+func (c *MessageService) Send(ctx context.Context, mp *MessageSendParams) (*MessageSendResponseBody, *Response, error) {
+	if mp == nil {
+		return nil, nil, errors.New("plivo: Send with nil parameters")
+	}
+	if err := checkNumbers("dst", mp.Dst, false); err != nil {
+		return nil, nil, err
+	}
+	if c.client.OptOut != nil && mp.Dst != "" {
+		if err := c.client.OptOut.Check(ctx, mp.Dst); err != nil {
+			return nil, nil, err
+		}
+	}
+	if mp.Transliterate {
+		p := *mp
+		p.Text = Transliterate(p.Text)
+		mp = &p
+	}
+	if mp.MaxSegments > 0 {
+		if seg := Segment(mp.Text); seg.Segments > mp.MaxSegments {
+			return nil, nil, fmt.Errorf("%w: %d %s segments, at most %d allowed", ErrTooManySegments, seg.Segments, seg.Encoding, mp.MaxSegments)
+		}
+	}
 	req, err := c.client.NewRequest("POST", c.client.authID+"/Message/", mp)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &MessageSendResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, aResp)
+	resp, err := c.client.Do(ctx, req, aResp)
 	return aResp, resp, err
 }
 
 type MessageGetAllParams struct {
-	Limit  int64 `json:"limit:omitempty"`
-	Offset int64 `json:"offset:omitempty"`
+	Limit  int64 `url:"limit,omitempty"`
+	Offset int64 `url:"offset,omitempty"`
 }
 
 type MessageGetAllResponseBody struct {
@@ -63,24 +99,42 @@ type MessageGetAllResponseBody struct {
 }
 
 // GetAll fetches all messages.
-func (s *MessageService) GetAll(p *MessageGetAllParams) ([]*Message, *Response, error) {
+func (s *MessageService) GetAll(ctx context.Context, p *MessageGetAllParams) ([]*Message, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Message/", p)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &MessageGetAllResponseBody{}
-	resp, err := s.client.Do(req, aResp)
-	resp.Meta = aResp.Meta
+	resp, err := s.client.Do(ctx, req, aResp)
+	if resp != nil {
+		resp.Meta = aResp.Meta
+	}
 	return aResp.Objects, resp, err
 }
 
+// GetAllPager returns a Pager which walks all messages matching p, page by page,
+// starting at p.Offset. A non-zero p.Limit sets the Pager's PageSize.
+func (s *MessageService) GetAllPager(ctx context.Context, p *MessageGetAllParams) *Pager[*Message] {
+	q := MessageGetAllParams{}
+	if p != nil {
+		q = *p
+	}
+	pg := NewPager(ctx, q.Offset, func(ctx context.Context, limit, offset int64) ([]*Message, *Meta, error) {
+		q.Limit, q.Offset = limit, offset
+		objs, resp, err := s.GetAll(ctx, &q)
+		return objs, pageMeta(resp), err
+	})
+	pg.PageSize = q.Limit
+	return pg
+}
+
 // Get fetches a specified message.
-func (s *MessageService) Get(id string) (*Message, *Response, error) {
+func (s *MessageService) Get(ctx context.Context, id string) (*Message, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Message/"+id+"/", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &Message{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return aResp, resp, err
 }
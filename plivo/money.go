@@ -0,0 +1,278 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// moneyScale is the number of decimal places a Money value holds exactly.
+const (
+	moneyScale = 9
+	moneyUnit  = 1e9
+)
+
+// Money is an exact decimal amount, such as a rate, a charge or a credit
+// balance, with nine decimal places. The zero value is 0.
+//
+// Money holds amounts up to about ±9.2 billion. Arithmetic beyond that range
+// saturates at MaxMoney or MinMoney instead of wrapping around.
+//
+// Fields of type Money are tagged omitzero, as omitempty has no effect on
+// structs, so that unset amounts are left out of request bodies.
+type Money struct {
+	nanos int64
+}
+
+var (
+	// MaxMoney is the largest amount a Money can hold, 9223372036.854775807.
+	MaxMoney = Money{math.MaxInt64}
+	// MinMoney is the smallest amount a Money can hold, -9223372036.854775808.
+	MinMoney = Money{math.MinInt64}
+)
+
+// saturate returns n nanos as Money, clamped to [MinMoney, MaxMoney].
+func saturate(n *big.Int) Money {
+	switch {
+	case n.IsInt64():
+		return Money{n.Int64()}
+	case n.Sign() > 0:
+		return MaxMoney
+	}
+	return MinMoney
+}
+
+// ParseMoney parses a decimal string such as "0.00350" or "-12". It fails if
+// the value has more than nine significant decimal places.
+func ParseMoney(s string) (Money, error) {
+	orig := s
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	intPart, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, frac = s[:i], s[i+1:]
+	}
+	frac = strings.TrimRight(frac, "0")
+	if intPart == "" && frac == "" || len(frac) > moneyScale || !isDigits(intPart) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("plivo: invalid amount %q", orig)
+	}
+
+	n := new(big.Int)
+	if intPart != "" {
+		n.SetString(intPart, 10)
+	}
+	n.Mul(n, big.NewInt(moneyUnit))
+	if frac != "" {
+		f, _ := new(big.Int).SetString(frac+strings.Repeat("0", moneyScale-len(frac)), 10)
+		n.Add(n, f)
+	}
+	if !n.IsInt64() {
+		return Money{}, fmt.Errorf("plivo: amount %q out of range", orig)
+	}
+	m := Money{n.Int64()}
+	if neg {
+		m.nanos = -m.nanos
+	}
+	return m, nil
+}
+
+// MustParseMoney is like ParseMoney but panics if s is invalid. It is meant
+// for constants in programs and tests.
+func MustParseMoney(s string) Money {
+	m, err := ParseMoney(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Add returns m + o, saturated to [MinMoney, MaxMoney].
+func (m Money) Add(o Money) Money {
+	s := m.nanos + o.nanos
+	switch {
+	case m.nanos > 0 && o.nanos > 0 && s < 0:
+		return MaxMoney
+	case m.nanos < 0 && o.nanos < 0 && s >= 0:
+		return MinMoney
+	}
+	return Money{s}
+}
+
+// Sub returns m - o, saturated to [MinMoney, MaxMoney].
+func (m Money) Sub(o Money) Money {
+	d := m.nanos - o.nanos
+	switch {
+	case m.nanos >= 0 && o.nanos < 0 && d < 0:
+		return MaxMoney
+	case m.nanos < 0 && o.nanos > 0 && d >= 0:
+		return MinMoney
+	}
+	return Money{d}
+}
+
+// Mul returns m multiplied by n, for example a per-segment rate by a number
+// of segments, saturated to [MinMoney, MaxMoney].
+func (m Money) Mul(n int64) Money {
+	return saturate(new(big.Int).Mul(big.NewInt(m.nanos), big.NewInt(n)))
+}
+
+// MulDuration returns a rate m charged per unit applied to d, for example
+// rate.MulDuration(90*time.Second, time.Minute) for a per-minute rate. The
+// result is rounded half away from zero to nine decimal places and saturated
+// to [MinMoney, MaxMoney].
+func (m Money) MulDuration(d, unit time.Duration) Money {
+	n := new(big.Int).Mul(big.NewInt(m.nanos), big.NewInt(int64(d)))
+	return saturate(divRound(n, big.NewInt(int64(unit))))
+}
+
+// divRound divides n by d, rounding half away from zero.
+func divRound(n, d *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	r.Abs(r).Mul(r, big.NewInt(2))
+	if r.Cmp(new(big.Int).Abs(d)) >= 0 {
+		if n.Sign()*d.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+// Cmp returns -1, 0 or +1 depending on whether m is less than, equal to or greater than o.
+func (m Money) Cmp(o Money) int {
+	switch {
+	case m.nanos < o.nanos:
+		return -1
+	case m.nanos > o.nanos:
+		return 1
+	}
+	return 0
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of m.
+func (m Money) Sign() int {
+	return m.Cmp(Money{})
+}
+
+// IsZero reports whether m is zero.
+func (m Money) IsZero() bool {
+	return m.nanos == 0
+}
+
+// Float64 returns the nearest float64 to m. Use it for display or statistics
+// only; arithmetic should stay on Money.
+func (m Money) Float64() float64 {
+	return float64(m.nanos) / float64(moneyUnit)
+}
+
+// String returns m in decimal form without trailing zeros, as in "0.0035".
+func (m Money) String() string {
+	s := m.StringFixed(moneyScale)
+	if strings.IndexByte(s, '.') >= 0 {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// StringFixed returns m rounded half away from zero to the given number of
+// decimal places, between 0 and 9, as in "0.00350" for five places.
+func (m Money) StringFixed(places int) string {
+	if places < 0 {
+		places = 0
+	}
+	if places > moneyScale {
+		places = moneyScale
+	}
+	div := big.NewInt(1)
+	for i := places; i < moneyScale; i++ {
+		div.Mul(div, big.NewInt(10))
+	}
+	n := divRound(big.NewInt(m.nanos), div)
+
+	sign := ""
+	if n.Sign() < 0 {
+		sign = "-"
+		n.Abs(n)
+	}
+	digits := n.String()
+	if len(digits) <= places {
+		digits = strings.Repeat("0", places+1-len(digits)) + digits
+	}
+	if places == 0 {
+		return sign + digits
+	}
+	return sign + digits[:len(digits)-places] + "." + digits[len(digits)-places:]
+}
+
+// MarshalJSON encodes m as a decimal string, as the API does.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(m.String())), nil
+}
+
+// UnmarshalJSON decodes a decimal given as a string or a number, which may
+// have an exponent. Null and empty strings decode to zero.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*m = Money{}
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		s, err := strconv.Unquote(string(data))
+		if err != nil {
+			return fmt.Errorf("plivo: invalid amount %s", data)
+		}
+		return m.UnmarshalText([]byte(s))
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("plivo: invalid amount %s", data)
+	}
+	r, ok := new(big.Rat).SetString(n.String())
+	if !ok {
+		return fmt.Errorf("plivo: invalid amount %s", data)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(moneyUnit))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return fmt.Errorf("plivo: amount %s out of range or too precise", data)
+	}
+	*m = Money{r.Num().Int64()}
+	return nil
+}
+
+// UnmarshalText decodes a decimal string, as found in callback parameters.
+func (m *Money) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*m = Money{}
+		return nil
+	}
+	parsed, err := ParseMoney(string(text))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
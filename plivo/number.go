@@ -3,37 +3,39 @@
 
 package plivo
 
+import "context"
+
 type NumberService struct {
 	client *Client
 }
 
 type Number struct {
-	VoiceEnabled bool   `json:"voice_enabled,omitempty"`
-	SMSEnabled   bool   `json:"sms_enabled,omitempty"`
-	Description  string `json:"description,omitempty"`
-	PlivoNumber  bool   `json:"plivo_number,omitempty"`
-	Number       string `json:"number,omitempty"`
-	NumberType   string `json:"number_type,omitempty"`
-	Application  string `json:"application,omitempty"`
-	AddedOn      string `json:"added_on,omitempty"`
-	ResourceURI  string `json:"resource_uri,omitempty"`
+	VoiceEnabled bool        `json:"voice_enabled,omitempty"`
+	SMSEnabled   bool        `json:"sms_enabled,omitempty"`
+	Description  string      `json:"description,omitempty"`
+	PlivoNumber  bool        `json:"plivo_number,omitempty"`
+	Number       PhoneNumber `json:"number,omitempty"`
+	NumberType   string      `json:"number_type,omitempty"`
+	Application  string      `json:"application,omitempty"`
+	AddedOn      Time        `json:"added_on,omitzero"`
+	ResourceURI  string      `json:"resource_uri,omitempty"`
 	// Rental-related fields
 	GroupID    string `json:"group_id,omitempty"`
 	Prefix     string `json:"string,omitempty"`
-	SetupRate  string `json:"setup_rate,omitempty"`
-	RentalRate string `json:"rental_rate,omitempty"`
+	SetupRate  Money  `json:"setup_rate,omitzero"`
+	RentalRate Money  `json:"rental_rate,omitzero"`
 	Stock      int64  `json:"stock,omitempty"`
-	VoiceRate  string `json:"voice_rate,omitempty"`
-	SMSRate    string `json:"sms_rate,omitempty"`
+	VoiceRate  Money  `json:"voice_rate,omitzero"`
+	SMSRate    Money  `json:"sms_rate,omitzero"`
 }
 
 type NumberGetAllParams struct {
-	NumberType       string `json:"number_type,omitempty"`
-	NumberStartswith string `json:"number_startswith,omitempty"`
-	Subaccount       string `json:"subaccount,omitempty"`
-	Services         string `json:"services,omitempty"`
-	Limit            int64  `json:"limit:omitempty"`
-	Offset           int64  `json:"offset:omitempty"`
+	NumberType       string `url:"number_type,omitempty"`
+	NumberStartswith string `url:"number_startswith,omitempty"`
+	Subaccount       string `url:"subaccount,omitempty"`
+	Services         string `url:"services,omitempty"`
+	Limit            int64  `url:"limit,omitempty"`
+	Offset           int64  `url:"offset,omitempty"`
 }
 
 type NumbersResponseBody struct {
@@ -43,26 +45,44 @@ type NumbersResponseBody struct {
 }
 
 // GetAll fetches all calls.
-func (s *NumberService) GetAll(p *NumberGetAllParams) ([]*Number, *Response, error) {
+func (s *NumberService) GetAll(ctx context.Context, p *NumberGetAllParams) ([]*Number, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Number/", p)
 
 	if err != nil {
 		return nil, nil, err
 	}
 	nResp := &NumbersResponseBody{}
-	resp, err := s.client.Do(req, nResp)
-	resp.Meta = nResp.Meta
+	resp, err := s.client.Do(ctx, req, nResp)
+	if resp != nil {
+		resp.Meta = nResp.Meta
+	}
 	return nResp.Objects, resp, err
 }
 
+// GetAllPager returns a Pager which walks all rented numbers matching p, page by page,
+// starting at p.Offset. A non-zero p.Limit sets the Pager's PageSize.
+func (s *NumberService) GetAllPager(ctx context.Context, p *NumberGetAllParams) *Pager[*Number] {
+	q := NumberGetAllParams{}
+	if p != nil {
+		q = *p
+	}
+	pg := NewPager(ctx, q.Offset, func(ctx context.Context, limit, offset int64) ([]*Number, *Meta, error) {
+		q.Limit, q.Offset = limit, offset
+		objs, resp, err := s.GetAll(ctx, &q)
+		return objs, pageMeta(resp), err
+	})
+	pg.PageSize = q.Limit
+	return pg
+}
+
 // Get gets details of a rented number.
-func (s *NumberService) Get(number string) (*Number, *Response, error) {
+func (s *NumberService) Get(ctx context.Context, number string) (*Number, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Number/"+number+"/", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 	nResp := &Number{}
-	resp, err := s.client.Do(req, nResp)
+	resp, err := s.client.Do(ctx, req, nResp)
 	return nResp, resp, err
 }
 
@@ -77,14 +97,14 @@ type NumberAddParams struct {
 }
 
 // Add adds a number from your own carrier.
-func (c *NumberService) Add(np *NumberAddParams) (*Response, error) {
+func (c *NumberService) Add(ctx context.Context, np *NumberAddParams) (*Response, error) {
 	req, err := c.client.NewRequest("POST", c.client.authID+"/Number/", np)
 	if err != nil {
 		return nil, err
 	}
 	nResp := &ModifyResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, nResp)
+	resp, err := c.client.Do(ctx, req, nResp)
 	return resp, err
 }
 
@@ -94,24 +114,24 @@ type NumberEditParams struct {
 }
 
 // Edit edits a number.
-func (c *NumberService) Edit(number string, np *NumberEditParams) (*Response, error) {
+func (c *NumberService) Edit(ctx context.Context, number string, np *NumberEditParams) (*Response, error) {
 	req, err := c.client.NewRequest("POST", c.client.authID+"/Number/"+number+"/", np)
 	if err != nil {
 		return nil, err
 	}
 	nResp := &ModifyResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, nResp)
+	resp, err := c.client.Do(ctx, req, nResp)
 	return resp, err
 }
 
 // Unrent unrents a number.
-func (s *NumberService) Unrent(number string) (*Response, error) {
+func (s *NumberService) Unrent(ctx context.Context, number string) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/Number/"+number+"/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
@@ -121,24 +141,26 @@ type NumberSearchParams struct {
 	Prefix     string `url:"prefix,omitempty"`
 	Region     string `url:"region,omitempty"`
 	Services   string `url:"services,omitempty"`
-	Limit      int64  `url:"limit:omitempty"`
-	Offset     int64  `url:"offset:omitempty"`
+	Limit      int64  `url:"limit,omitempty"`
+	Offset     int64  `url:"offset,omitempty"`
 }
 
-func (s *NumberService) Search(sp *NumberSearchParams) ([]*Number, *Response, error) {
+func (s *NumberService) Search(ctx context.Context, sp *NumberSearchParams) ([]*Number, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/AvailableNumberGroup/", sp)
 
 	if err != nil {
 		return nil, nil, err
 	}
 	nResp := &NumbersResponseBody{}
-	resp, err := s.client.Do(req, nResp)
-	resp.Meta = nResp.Meta
+	resp, err := s.client.Do(ctx, req, nResp)
+	if resp != nil {
+		resp.Meta = nResp.Meta
+	}
 	return nResp.Objects, resp, err
 }
 
 type NumberRentalParams struct {
-	Quantity int64  `json:"quantity:omitempty"`
+	Quantity int64  `json:"quantity,omitempty"`
 	AppID    string `json:"app_id,omitempty"`
 }
 
@@ -150,17 +172,17 @@ type NumberRentalResponseBody struct {
 }
 
 type NumberRental struct {
-	Number string `json:"number"`
+	Number PhoneNumber `json:"number"`
 }
 
 // Rent rents a number.
-func (c *NumberService) Rent(gid string, np *NumberRentalParams) ([]*NumberRental, *Response, error) {
+func (c *NumberService) Rent(ctx context.Context, gid string, np *NumberRentalParams) ([]*NumberRental, *Response, error) {
 	req, err := c.client.NewRequest("POST", c.client.authID+"/AvailableNumberGroup/"+gid+"/", np)
 	if err != nil {
 		return nil, nil, err
 	}
 	nResp := &NumberRentalResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := c.client.Do(req, nResp)
+	resp, err := c.client.Do(ctx, req, nResp)
 	return nResp.Numbers, resp, err
 }
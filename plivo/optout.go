@@ -0,0 +1,278 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrOptedOut matches the *OptOutError returned by MessageService.Send for
+// a destination which opted out of messages.
+var ErrOptedOut = errors.New("plivo: destination opted out")
+
+// OptOutError reports a message refused because its destination opted out.
+type OptOutError struct {
+	Number PhoneNumber
+}
+
+func (e *OptOutError) Error() string {
+	return "plivo: " + string(e.Number) + " opted out of messages"
+}
+
+// Is reports whether target is ErrOptedOut.
+func (e *OptOutError) Is(target error) bool {
+	return target == ErrOptedOut
+}
+
+// OptOutStore records the numbers which opted out of messages.
+// Implementations must be safe for concurrent use.
+type OptOutStore interface {
+	OptOut(ctx context.Context, n PhoneNumber) error
+	OptIn(ctx context.Context, n PhoneNumber) error
+	IsOptedOut(ctx context.Context, n PhoneNumber) (bool, error)
+}
+
+// MemoryOptOutStore is an OptOutStore held in memory.
+type MemoryOptOutStore struct {
+	mu      sync.Mutex
+	numbers map[PhoneNumber]bool
+}
+
+// NewMemoryOptOutStore returns an empty MemoryOptOutStore.
+func NewMemoryOptOutStore() *MemoryOptOutStore {
+	return &MemoryOptOutStore{numbers: make(map[PhoneNumber]bool)}
+}
+
+// OptOut implements OptOutStore.
+func (s *MemoryOptOutStore) OptOut(ctx context.Context, n PhoneNumber) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.numbers[n] = true
+	return nil
+}
+
+// OptIn implements OptOutStore.
+func (s *MemoryOptOutStore) OptIn(ctx context.Context, n PhoneNumber) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.numbers, n)
+	return nil
+}
+
+// IsOptedOut implements OptOutStore.
+func (s *MemoryOptOutStore) IsOptedOut(ctx context.Context, n PhoneNumber) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.numbers[n], nil
+}
+
+// FileOptOutStore is an OptOutStore kept in a file, one number per line. The
+// file is rewritten on every change, so that opt-outs survive restarts.
+type FileOptOutStore struct {
+	path string
+	mem  *MemoryOptOutStore
+}
+
+// NewFileOptOutStore returns a FileOptOutStore loaded from path, which need
+// not exist yet.
+func NewFileOptOutStore(path string) (*FileOptOutStore, error) {
+	s := &FileOptOutStore{path: path, mem: NewMemoryOptOutStore()}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if n := strings.TrimSpace(sc.Text()); n != "" {
+			s.mem.numbers[PhoneNumber(n)] = true
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// OptOut implements OptOutStore.
+func (s *FileOptOutStore) OptOut(ctx context.Context, n PhoneNumber) error {
+	return s.update(n, true)
+}
+
+// OptIn implements OptOutStore.
+func (s *FileOptOutStore) OptIn(ctx context.Context, n PhoneNumber) error {
+	return s.update(n, false)
+}
+
+// IsOptedOut implements OptOutStore.
+func (s *FileOptOutStore) IsOptedOut(ctx context.Context, n PhoneNumber) (bool, error) {
+	return s.mem.IsOptedOut(ctx, n)
+}
+
+// update sets whether n opted out and rewrites the file if that changed it.
+func (s *FileOptOutStore) update(n PhoneNumber, out bool) error {
+	s.mem.mu.Lock()
+	defer s.mem.mu.Unlock()
+	if s.mem.numbers[n] == out {
+		return nil
+	}
+	if out {
+		s.mem.numbers[n] = true
+	} else {
+		delete(s.mem.numbers, n)
+	}
+	if err := s.write(); err != nil {
+		// Keep memory in step with the file.
+		if out {
+			delete(s.mem.numbers, n)
+		} else {
+			s.mem.numbers[n] = true
+		}
+		return err
+	}
+	return nil
+}
+
+// write replaces the file with the current numbers, through a temporary file
+// synced to disk before the rename so that a crash cannot leave it truncated.
+func (s *FileOptOutStore) write() error {
+	numbers := make([]string, 0, len(s.mem.numbers))
+	for n := range s.mem.numbers {
+		numbers = append(numbers, string(n))
+	}
+	sort.Strings(numbers)
+
+	f, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, n := range numbers {
+		if _, err = w.WriteString(n + "\n"); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
+
+// OptOutManager handles the STOP, START and HELP keywords of inbound
+// messages and, set as Client.OptOut, stops messages to numbers which opted
+// out. Keywords match a whole message, ignoring case and surrounding space.
+// Numbers in international form are stored and checked in the canonical form
+// of ParsePhoneNumber, so that "+1 415 555 0100" matches "14155550100".
+type OptOutManager struct {
+	Store OptOutStore
+
+	StopKeywords  []string
+	StartKeywords []string
+	HelpKeywords  []string
+
+	// Confirmation replies to each kind of keyword. No reply is sent if empty.
+	StopReply  string
+	StartReply string
+	HelpReply  string
+}
+
+// NewOptOutManager returns an OptOutManager using store, with the usual
+// keywords and confirmation replies.
+func NewOptOutManager(store OptOutStore) *OptOutManager {
+	return &OptOutManager{
+		Store:         store,
+		StopKeywords:  []string{"STOP", "STOPALL", "UNSUBSCRIBE", "CANCEL", "END", "QUIT"},
+		StartKeywords: []string{"START", "YES", "UNSTOP"},
+		HelpKeywords:  []string{"HELP", "INFO"},
+		StopReply:     "You have been unsubscribed and will receive no further messages. Reply START to resubscribe.",
+		StartReply:    "You have been resubscribed. Reply STOP to unsubscribe.",
+		HelpReply:     "Reply STOP to unsubscribe or START to resubscribe.",
+	}
+}
+
+func matchKeyword(text string, keywords []string) bool {
+	text = strings.TrimSpace(text)
+	for _, k := range keywords {
+		if strings.EqualFold(text, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// canonical returns n as parsed by ParsePhoneNumber, or as is if it does not parse.
+func canonical(n PhoneNumber) PhoneNumber {
+	if p, err := ParsePhoneNumber(string(n), ""); err == nil {
+		return p
+	}
+	return n
+}
+
+// confirm returns a reply with text, or nil if text is empty.
+func confirm(text string) *Reply {
+	if text == "" {
+		return nil
+	}
+	return &Reply{Text: text}
+}
+
+// Handle returns an InboundFunc which records opt-outs and opt-ins from
+// keyword messages and replies to them, passing all other messages to next,
+// which may be nil.
+func (m *OptOutManager) Handle(next InboundFunc) InboundFunc {
+	return func(ctx context.Context, msg *InboundMessage) (*Reply, error) {
+		switch {
+		case matchKeyword(msg.Text, m.StopKeywords):
+			if err := m.Store.OptOut(ctx, canonical(msg.From)); err != nil {
+				return nil, err
+			}
+			return confirm(m.StopReply), nil
+		case matchKeyword(msg.Text, m.StartKeywords):
+			if err := m.Store.OptIn(ctx, canonical(msg.From)); err != nil {
+				return nil, err
+			}
+			return confirm(m.StartReply), nil
+		case matchKeyword(msg.Text, m.HelpKeywords):
+			return confirm(m.HelpReply), nil
+		case next != nil:
+			return next(ctx, msg)
+		}
+		return nil, nil
+	}
+}
+
+// Check returns an *OptOutError for the first of the '<'-separated
+// destinations in dst which opted out.
+func (m *OptOutManager) Check(ctx context.Context, dst PhoneNumber) error {
+	for _, d := range strings.Split(string(dst), "<") {
+		out, err := m.Store.IsOptedOut(ctx, canonical(PhoneNumber(d)))
+		if err != nil {
+			return err
+		}
+		if out {
+			return &OptOutError{Number: PhoneNumber(d)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOptOut(t *testing.T) {
+	path := t.TempDir() + "/optouts"
+	store, err := NewFileOptOutStore(path)
+	if err != nil {
+		t.Fatalf("NewFileOptOutStore failed: %v", err)
+	}
+
+	var sent []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p MessageSendParams
+		json.NewDecoder(r.Body).Decode(&p)
+		sent = append(sent, string(p.Dst))
+		dst := strings.Split(string(p.Dst), "<")
+		json.NewEncoder(w).Encode(map[string]interface{}{"message_uuid": dst})
+	}))
+	defer api.Close()
+	client := newTestClient(api)
+	client.OptOut = NewOptOutManager(store)
+
+	var passed []string
+	handle := client.OptOut.Handle(func(ctx context.Context, m *InboundMessage) (*Reply, error) {
+		passed = append(passed, m.Text)
+		return nil, nil
+	})
+	inbound := func(from PhoneNumber, text string) *Reply {
+		reply, err := handle(ctx, &InboundMessage{From: from, To: "14155550100", Text: text})
+		if err != nil {
+			t.Fatalf("handling %q failed: %v", text, err)
+		}
+		return reply
+	}
+
+	if r := inbound("14155550101", " stop "); r == nil || r.Text != client.OptOut.StopReply {
+		t.Errorf("STOP reply = %+v", r)
+	}
+	if r := inbound("14155550101", "help"); r == nil || r.Text != client.OptOut.HelpReply {
+		t.Errorf("HELP reply = %+v", r)
+	}
+	inbound("14155550102", "Please stop by later")
+
+	_, _, err = client.Message.Send(ctx, &MessageSendParams{Src: "14155550100", Dst: "14155550102<14155550101", Text: "Hi"})
+	var oerr *OptOutError
+	if !errors.As(err, &oerr) || oerr.Number != "14155550101" || !errors.Is(err, ErrOptedOut) {
+		t.Errorf("Send to opted out number returned %v", err)
+	}
+	res, err := client.Message.SendBulk(ctx, &MessageSendParams{Src: "14155550100", Text: "Hi"}, []PhoneNumber{"14155550101", "14155550102"})
+	if !errors.Is(err, ErrOptedOut) || len(res.MessageUUID) != 1 || res.MessageUUID["14155550102"] == "" {
+		t.Errorf("SendBulk = %+v, %v", res, err)
+	}
+
+	// Opt-outs survive a restart.
+	store, err = NewFileOptOutStore(path)
+	if err != nil {
+		t.Fatalf("reloading store failed: %v", err)
+	}
+	if out, _ := store.IsOptedOut(ctx, "14155550101"); !out {
+		t.Errorf("opt-out lost on reload")
+	}
+	client.OptOut.Store = store
+
+	if r := inbound("14155550101", "START"); r == nil || r.Text != client.OptOut.StartReply {
+		t.Errorf("START reply = %+v", r)
+	}
+	if _, _, err := client.Message.Send(ctx, &MessageSendParams{Src: "14155550100", Dst: "14155550101", Text: "Hi"}); err != nil {
+		t.Errorf("Send after START failed: %v", err)
+	}
+	if b, _ := ioutil.ReadFile(path); len(b) != 0 {
+		t.Errorf("store file = %q after START", b)
+	}
+	if fmt.Sprint(passed) != "[Please stop by later]" || fmt.Sprint(sent) != "[14155550102 14155550101]" {
+		t.Errorf("passed %q, sent %q", passed, sent)
+	}
+
+	// Numbers match whatever international form they are written in.
+	inbound("+1 (415) 555-0103", "STOP")
+	if out, _ := store.IsOptedOut(ctx, "14155550103"); !out {
+		t.Errorf("opt-out from +1 (415) 555-0103 not stored as 14155550103")
+	}
+	if _, _, err := client.Message.Send(ctx, &MessageSendParams{Src: "14155550100", Dst: "14155550103", Text: "Hi"}); !errors.Is(err, ErrOptedOut) {
+		t.Errorf("Send to 14155550103 returned %v, want opted out", err)
+	}
+	for _, dst := range []PhoneNumber{"+14155550103", "0014155550103", "14155550102<+1 415 555 0103"} {
+		if err := client.OptOut.Check(ctx, dst); !errors.Is(err, ErrOptedOut) {
+			t.Errorf("Check(%s) = %v, want opted out", dst, err)
+		}
+	}
+	inbound("001 415 555 0103", "START")
+	if _, _, err := client.Message.Send(ctx, &MessageSendParams{Src: "14155550100", Dst: "14155550103", Text: "Hi"}); err != nil {
+		t.Errorf("Send after START from another form failed: %v", err)
+	}
+}
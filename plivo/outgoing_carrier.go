@@ -3,15 +3,17 @@
 
 package plivo
 
+import "context"
+
 type OutgoingCarrierService struct {
 	client *Client
 }
 
 type OutgoingCarrier struct {
-	CarrierID   string
-	IPSet       string
-	Name        string
-	ResourceURI string
+	CarrierID   string `json:"carrier_id,omitempty"`
+	IPSet       string `json:"ip_set,omitempty"`
+	Name        string `json:"name,omitempty"`
+	ResourceURI string `json:"resource_uri,omitempty"`
 }
 
 type OutgoingCarrierGetAllResponseBody struct {
@@ -22,40 +24,43 @@ type OutgoingCarrierGetAllResponseBody struct {
 
 type OutgoingCarrierGetAllParams struct {
 	// Query parameters.
-	Name   string `json:"name,omitempty"`
-	Limit  int64  `json:"limit:omitempty"`
-	Offset int64  `json:"offset:omitempty"`
+	Name   string `url:"name,omitempty"`
+	Limit  int64  `url:"limit,omitempty"`
+	Offset int64  `url:"offset,omitempty"`
 }
 
-func (s *OutgoingCarrierService) GetAll(p *OutgoingCarrierGetAllParams) ([]*OutgoingCarrier, *Response, error) {
+// GetAll fetches all outgoing carriers.
+func (s *OutgoingCarrierService) GetAll(ctx context.Context, p *OutgoingCarrierGetAllParams) ([]*OutgoingCarrier, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/OutgoingCarrier/", p)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &OutgoingCarrierGetAllResponseBody{}
-	resp, err := s.client.Do(req, aResp)
-	resp.Meta = aResp.Meta
+	resp, err := s.client.Do(ctx, req, aResp)
+	if resp != nil {
+		resp.Meta = aResp.Meta
+	}
 	return aResp.Objects, resp, err
 }
 
 // Get fetches a specified carrier.
-func (s *OutgoingCarrierService) Get(carrierID string) (*OutgoingCarrier, *Response, error) {
+func (s *OutgoingCarrierService) Get(ctx context.Context, carrierID string) (*OutgoingCarrier, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/OutgoingCarrier/"+carrierID+"/", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &OutgoingCarrier{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return aResp, resp, err
 }
 
 // Remove removes a carrier, and deletes all numbers associated with the carrier.
-func (s *OutgoingCarrierService) Remove(carrierID string) (*Response, error) {
+func (s *OutgoingCarrierService) Remove(ctx context.Context, carrierID string) (*Response, error) {
 	req, err := s.client.NewRequest("DELETE", s.client.authID+"/OutgoingCarrier/"+carrierID+"/", nil)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := s.client.Do(req, nil)
+	resp, err := s.client.Do(ctx, req, nil)
 	return resp, err
 }
 
@@ -77,14 +82,14 @@ type OutgoingCarrierResponseBody struct {
 }
 
 // Add adds an outgoing carrier.
-func (s *OutgoingCarrierService) Add(p *OutgoingCarrierAddParams) (*Response, error) {
+func (s *OutgoingCarrierService) Add(ctx context.Context, p *OutgoingCarrierAddParams) (*Response, error) {
 	req, err := s.client.NewRequest("POST", s.client.authID+"/OutgoingCarrier/", p)
 	if err != nil {
 		return nil, err
 	}
 	aResp := &OutgoingCarrierResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return resp, err
 }
 
@@ -94,13 +99,13 @@ type OutgoingCarrierModifyParams struct {
 }
 
 // Modify updates an outgoing carrier.
-func (s *OutgoingCarrierService) Modify(p *OutgoingCarrierModifyParams) (*Response, error) {
-	req, err := s.client.NewRequest("POST", s.client.authID+"/OutgoingCarrier/", p)
+func (s *OutgoingCarrierService) Modify(ctx context.Context, carrierID string, p *OutgoingCarrierModifyParams) (*Response, error) {
+	req, err := s.client.NewRequest("POST", s.client.authID+"/OutgoingCarrier/"+carrierID+"/", p)
 	if err != nil {
 		return nil, err
 	}
 	aResp := &OutgoingCarrierResponseBody{}
 	req.Header.Add("Content-Type", "application/json")
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return resp, err
 }
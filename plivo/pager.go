@@ -0,0 +1,153 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import "context"
+
+// maxPageSize is the largest page the API returns.
+const maxPageSize = 20
+
+// PageFunc fetches a single page of at most limit objects starting at offset.
+type PageFunc[T any] func(ctx context.Context, limit, offset int64) ([]T, *Meta, error)
+
+// Pager walks every page of a list endpoint lazily. Set its fields before the
+// first call to Next, then iterate:
+//
+//	p := client.Call.GetAllPager(ctx, &plivo.CallGetAllParams{})
+//	for p.Next() {
+//		call := p.Value()
+//		...
+//	}
+//	if err := p.Err(); err != nil {
+//		...
+//	}
+type Pager[T any] struct {
+	// PageSize is the number of objects requested per page. It defaults to,
+	// and is capped at, 20, the most the API returns.
+	PageSize int64
+
+	// MaxItems stops the iteration after that many objects. Zero means no limit.
+	MaxItems int64
+
+	// Prefetch fetches the next page in the background while the current one
+	// is being consumed.
+	Prefetch bool
+
+	ctx     context.Context
+	fetch   PageFunc[T]
+	offset  int64
+	count   int64
+	buf     []T
+	cur     T
+	done    bool
+	err     error
+	pending chan page[T]
+}
+
+type page[T any] struct {
+	items []T
+	meta  *Meta
+	err   error
+}
+
+// NewPager returns a Pager which calls fetch for each page, starting at offset.
+func NewPager[T any](ctx context.Context, offset int64, fetch PageFunc[T]) *Pager[T] {
+	return &Pager[T]{ctx: ctx, fetch: fetch, offset: offset}
+}
+
+// Next advances to the next object, fetching a new page if needed. It returns
+// false when the iteration is over or an error occurred.
+func (p *Pager[T]) Next() bool {
+	if p.MaxItems > 0 && p.count >= p.MaxItems {
+		return false
+	}
+	for len(p.buf) == 0 {
+		if p.done || p.err != nil {
+			return false
+		}
+		p.nextPage()
+	}
+	p.cur, p.buf = p.buf[0], p.buf[1:]
+	p.count++
+	return true
+}
+
+// Value returns the current object.
+func (p *Pager[T]) Value() T {
+	return p.cur
+}
+
+// Err returns the first error encountered while fetching pages.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// nextPage loads the next page into the buffer, using the prefetched one if any.
+func (p *Pager[T]) nextPage() {
+	var pg page[T]
+	if p.pending != nil {
+		pg = <-p.pending
+		p.pending = nil
+	} else {
+		pg = p.load(p.offset, p.limit())
+	}
+	if pg.err != nil {
+		p.err = pg.err
+		return
+	}
+
+	p.buf = pg.items
+	p.offset += int64(len(pg.items))
+	switch {
+	case len(pg.items) == 0:
+		p.done = true
+	case pg.meta != nil:
+		p.done = pg.meta.Next == ""
+	default:
+		p.done = int64(len(pg.items)) < p.pageSize()
+	}
+	if p.MaxItems > 0 && p.count+int64(len(p.buf)) >= p.MaxItems {
+		p.done = true
+	}
+
+	if !p.done && p.Prefetch {
+		p.pending = make(chan page[T], 1)
+		go func(ch chan page[T], offset, limit int64) {
+			ch <- p.load(offset, limit)
+		}(p.pending, p.offset, p.limit())
+	}
+}
+
+// load fetches a page. It must not touch the Pager's state since it may run
+// in a prefetch goroutine.
+func (p *Pager[T]) load(offset, limit int64) page[T] {
+	items, meta, err := p.fetch(p.ctx, limit, offset)
+	return page[T]{items, meta, err}
+}
+
+// limit returns the size of the next page, capped to the items still wanted.
+func (p *Pager[T]) limit() int64 {
+	limit := p.pageSize()
+	if p.MaxItems > 0 {
+		if rest := p.MaxItems - p.count - int64(len(p.buf)); rest < limit {
+			limit = rest
+		}
+	}
+	return limit
+}
+
+func (p *Pager[T]) pageSize() int64 {
+	if p.PageSize > 0 && p.PageSize < maxPageSize {
+		return p.PageSize
+	}
+	return maxPageSize
+}
+
+// pageMeta returns the pagination metadata of a list response, if any.
+func pageMeta(resp *Response) *Meta {
+	if resp == nil {
+		return nil
+	}
+	return resp.Meta
+}
@@ -0,0 +1,231 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PhoneNumber is a phone number in the digits-only E.164 form used by the
+// API, such as "14155550100". Several numbers may be joined with '<' where
+// the API accepts bulk destinations, and SIP URIs such as
+// "sip:alice@example.com" may be used where the API accepts endpoints.
+//
+// A string literal converts to a PhoneNumber as is; use ParsePhoneNumber to
+// normalise and check user input before it is sent.
+type PhoneNumber string
+
+// region describes the numbering plan of a country or territory.
+type region struct {
+	code   string // country calling code
+	trunk  string // national trunk prefix dropped in international form
+	minLen int    // shortest national significant number
+	maxLen int    // longest national significant number
+}
+
+// regions maps ISO 3166-1 alpha-2 codes to their numbering plans.
+var regions = map[string]region{
+	"AE": {"971", "0", 8, 9},
+	"AT": {"43", "0", 4, 13},
+	"AU": {"61", "0", 9, 9},
+	"BE": {"32", "0", 8, 9},
+	"BR": {"55", "0", 10, 11},
+	"CA": {"1", "1", 10, 10},
+	"CH": {"41", "0", 9, 9},
+	"CN": {"86", "0", 7, 12},
+	"DE": {"49", "0", 6, 13},
+	"DK": {"45", "", 8, 8},
+	"ES": {"34", "", 9, 9},
+	"FI": {"358", "0", 5, 12},
+	"FR": {"33", "0", 9, 9},
+	"GB": {"44", "0", 9, 10},
+	"HK": {"852", "", 8, 8},
+	"ID": {"62", "0", 8, 12},
+	"IE": {"353", "0", 7, 9},
+	"IL": {"972", "0", 8, 9},
+	"IN": {"91", "0", 10, 10},
+	"IT": {"39", "", 6, 11},
+	"JP": {"81", "0", 9, 10},
+	"KE": {"254", "0", 9, 9},
+	"MX": {"52", "", 10, 10},
+	"MY": {"60", "0", 8, 10},
+	"NG": {"234", "0", 8, 10},
+	"NL": {"31", "0", 9, 9},
+	"NO": {"47", "", 8, 8},
+	"NZ": {"64", "0", 8, 10},
+	"PH": {"63", "0", 8, 10},
+	"PL": {"48", "", 9, 9},
+	"PT": {"351", "", 9, 9},
+	"RU": {"7", "8", 10, 10},
+	"SE": {"46", "0", 7, 10},
+	"SG": {"65", "", 8, 8},
+	"US": {"1", "1", 10, 10},
+	"ZA": {"27", "0", 9, 9},
+}
+
+// countryCodes holds every assigned country calling code. No code is a
+// prefix of another, so a number's code is its unique prefix in this set.
+var countryCodes = map[string]bool{}
+
+func init() {
+	for _, list := range []string{
+		"1 7 20 27 30 31 32 33 34 36 39 40 41 43 44 45 46 47 48 49",
+		"51 52 53 54 55 56 57 58 60 61 62 63 64 65 66 81 82 84 86 90 91 92 93 94 95 98",
+		"211 212 213 216 218 220 221 222 223 224 225 226 227 228 229 230 231 232 233 234",
+		"235 236 237 238 239 240 241 242 243 244 245 246 247 248 249 250 251 252 253 254",
+		"255 256 257 258 260 261 262 263 264 265 266 267 268 269 290 291 297 298 299",
+		"350 351 352 353 354 355 356 357 358 359 370 371 372 373 374 375 376 377 378 379",
+		"380 381 382 383 385 386 387 389 420 421 423",
+		"500 501 502 503 504 505 506 507 508 509 590 591 592 593 594 595 596 597 598 599",
+		"670 672 673 674 675 676 677 678 679 680 681 682 683 685 686 687 688 689 690 691 692",
+		"800 808 850 852 853 855 856 870 878 880 881 882 883 886 888",
+		"960 961 962 963 964 965 966 967 968 970 971 972 973 974 975 976 977 979",
+		"992 993 994 995 996 998",
+	} {
+		for _, code := range strings.Fields(list) {
+			countryCodes[code] = true
+		}
+	}
+}
+
+// ParsePhoneNumber parses a number written in international form, such as
+// "+44 20 7946 0958", "0044 20 7946 0958" or "442079460958", or in the
+// national form of defaultRegion, such as "020 7946 0958" with "GB" or
+// "(415) 555-0100" with "US". Spaces, dots, dashes, slashes and brackets are
+// ignored. An empty defaultRegion accepts international forms only.
+func ParsePhoneNumber(s, defaultRegion string) (PhoneNumber, error) {
+	var b strings.Builder
+	for i, c := range strings.TrimSpace(s) {
+		switch {
+		case c >= '0' && c <= '9':
+			b.WriteRune(c)
+		case c == '+' && i == 0:
+			b.WriteRune(c)
+		case strings.ContainsRune(" .-/()", c):
+		default:
+			return "", fmt.Errorf("plivo: invalid phone number %q", s)
+		}
+	}
+	digits := b.String()
+
+	var n PhoneNumber
+	r, hasRegion := regions[strings.ToUpper(defaultRegion)]
+	switch {
+	case strings.HasPrefix(digits, "+"):
+		n = PhoneNumber(digits[1:])
+	case strings.HasPrefix(digits, "00"):
+		n = PhoneNumber(digits[2:])
+	case hasRegion && r.code == "1" && strings.HasPrefix(digits, "011"):
+		n = PhoneNumber(digits[3:])
+	case hasRegion:
+		national := digits
+		if r.trunk != "" && strings.HasPrefix(national, r.trunk) && len(national)-len(r.trunk) >= r.minLen {
+			national = national[len(r.trunk):]
+		}
+		n = PhoneNumber(r.code + national)
+	case defaultRegion != "":
+		return "", fmt.Errorf("plivo: unknown region %q", defaultRegion)
+	default:
+		n = PhoneNumber(digits)
+	}
+	if !n.Valid() {
+		return "", fmt.Errorf("plivo: invalid phone number %q", s)
+	}
+	return n, nil
+}
+
+// MustParsePhoneNumber is like ParsePhoneNumber but panics if s is invalid.
+func MustParsePhoneNumber(s, defaultRegion string) PhoneNumber {
+	n, err := ParsePhoneNumber(s, defaultRegion)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// PhoneNumbers converts a slice of strings to phone numbers, as is.
+func PhoneNumbers[S ~string](numbers []S) []PhoneNumber {
+	ns := make([]PhoneNumber, len(numbers))
+	for i, n := range numbers {
+		ns[i] = PhoneNumber(n)
+	}
+	return ns
+}
+
+// countryCode returns the country calling code prefix of n, or "" if there is none.
+func (n PhoneNumber) countryCode() string {
+	for i := 1; i <= 3 && i <= len(n); i++ {
+		if countryCodes[string(n[:i])] {
+			return string(n[:i])
+		}
+	}
+	return ""
+}
+
+// CountryCode returns the country calling code of n, such as 44, or 0 if it
+// has none.
+func (n PhoneNumber) CountryCode() int {
+	code, _ := strconv.Atoi(n.countryCode())
+	return code
+}
+
+// NationalNumber returns n without its country calling code.
+func (n PhoneNumber) NationalNumber() string {
+	return string(n[len(n.countryCode()):])
+}
+
+// Valid reports whether n is a single digits-only E.164 number with an
+// assigned country code and, for the regions ParsePhoneNumber knows, a
+// national number of plausible length.
+func (n PhoneNumber) Valid() bool {
+	if len(n) > 15 || !isDigits(string(n)) {
+		return false
+	}
+	code := n.countryCode()
+	if code == "" {
+		return false
+	}
+	national := len(n) - len(code)
+	if national < 4 {
+		return false
+	}
+	for _, r := range regions {
+		if r.code == code {
+			return national >= r.minLen && national <= r.maxLen
+		}
+	}
+	return true
+}
+
+// E164 returns n with a leading '+', as in "+14155550100".
+func (n PhoneNumber) E164() string {
+	return "+" + string(n)
+}
+
+// String returns n as sent to the API.
+func (n PhoneNumber) String() string {
+	return string(n)
+}
+
+// isSIP reports whether n is a SIP URI rather than a phone number.
+func (n PhoneNumber) isSIP() bool {
+	return strings.HasPrefix(string(n), "sip:")
+}
+
+// checkNumbers returns an error if any of the '<'-separated destinations in n
+// is neither a valid phone number nor, when sip is set, a SIP URI. Empty
+// values are left for the API to reject.
+func checkNumbers(field string, n PhoneNumber, sip bool) error {
+	if n == "" {
+		return nil
+	}
+	for _, d := range strings.Split(string(n), "<") {
+		if d := PhoneNumber(d); !d.Valid() && !(sip && d.isSIP()) {
+			return fmt.Errorf("plivo: invalid %s number %q", field, d)
+		}
+	}
+	return nil
+}
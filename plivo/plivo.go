@@ -5,13 +5,15 @@ package plivo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
-
-	"github.com/google/go-querystring/query"
+	"sort"
+	"strings"
 )
 
 const (
@@ -32,6 +34,19 @@ type Client struct {
 	// User agent used when communicating the API.
 	UserAgent string
 
+	// Retry policy applied to transient failures. A nil policy disables retries.
+	RetryPolicy *RetryPolicy
+
+	// Optional client-side rate limiters. Limiter applies to every request,
+	// CallLimiter to call creation and MessageLimiter to message sending.
+	Limiter        *RateLimiter
+	CallLimiter    *RateLimiter
+	MessageLimiter *RateLimiter
+
+	// Optional opt-out manager. If set, messages to numbers which opted out
+	// are refused with an *OptOutError.
+	OptOut *OptOutManager
+
 	// Services used for talking to different parts of the API.
 	Account     *AccountService
 	Application *ApplicationService
@@ -40,6 +55,11 @@ type Client struct {
 	Number      *NumberService
 	Endpoint    *EndpointService
 	Conference  *ConferenceService
+	Pricing     *PricingService
+	Recording   *RecordingService
+
+	IncomingCarrier *IncomingCarrierService
+	OutgoingCarrier *OutgoingCarrierService
 
 	authID    string
 	authToken string
@@ -53,7 +73,9 @@ func NewClient(client *http.Client, authID, authToken string) *Client {
 		client = http.DefaultClient
 	}
 
-	c := &Client{client: client, BaseURL: baseURL, UserAgent: userAgent, authID: authID, authToken: authToken}
+	retryPolicy := DefaultRetryPolicy
+
+	c := &Client{client: client, BaseURL: baseURL, UserAgent: userAgent, RetryPolicy: &retryPolicy, authID: authID, authToken: authToken}
 	c.Account = &AccountService{client: c}
 	c.Application = &ApplicationService{client: c}
 	c.Call = &CallService{client: c}
@@ -61,10 +83,16 @@ func NewClient(client *http.Client, authID, authToken string) *Client {
 	c.Number = &NumberService{client: c}
 	c.Endpoint = &EndpointService{client: c}
 	c.Conference = &ConferenceService{client: c}
+	c.Pricing = &PricingService{client: c}
+	c.Recording = &RecordingService{client: c}
+	c.IncomingCarrier = &IncomingCarrierService{client: c}
+	c.OutgoingCarrier = &OutgoingCarrierService{client: c}
 	return c
 }
 
-// NewRequest creates an API request
+// NewRequest creates an API request. The body of a GET request is encoded
+// in the query string, merged with any query already present in urlStr;
+// other bodies are encoded as JSON.
 func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Request, error) {
 	rel, err := url.Parse(urlStr)
 	if err != nil {
@@ -74,14 +102,13 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 	u := c.BaseURL.ResolveReference(rel)
 
 	buf := new(bytes.Buffer)
-	params := ""
 	if body != nil {
 		if method == "GET" {
-			v, err := query.Values(body)
+			q, err := encodeQuery(u.Query(), body)
 			if err != nil {
 				return nil, err
 			}
-			params = "?" + v.Encode()
+			u.RawQuery = q
 		} else {
 			err := json.NewEncoder(buf).Encode(body)
 			if err != nil {
@@ -90,7 +117,7 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 		}
 	}
 
-	req, err := http.NewRequest(method, u.String()+params, buf)
+	req, err := http.NewRequest(method, u.String(), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -103,12 +130,12 @@ func (c *Client) NewRequest(method, urlStr string, body interface{}) (*http.Requ
 
 // Meta contains response metadata. This is usually pagination information.
 type Meta struct {
-	Previous string
-	Next     string
+	Previous string `json:"previous"`
+	Next     string `json:"next"`
 
-	TotalCount int64
-	Offset     int64
-	Limit      int64
+	TotalCount int64 `json:"total_count"`
+	Offset     int64 `json:"offset"`
+	Limit      int64 `json:"limit"`
 }
 
 // Response is a Plivo API response. This wraps the standard http.Response
@@ -127,9 +154,22 @@ func newResponse(r *http.Response) *Response {
 
 // Do sends an API request and returns the API response. The response is returned as an error if one occurs
 // or an attempt is made to decode it into v and the result of this operation returned if it fails.
-func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
-	resp, err := c.client.Do(req)
+//
+// The provided ctx must be non-nil. If it is canceled or times out, ctx.Err() will be returned.
+func (c *Client) Do(ctx context.Context, req *http.Request, v interface{}) (*Response, error) {
+	if ctx == nil {
+		return nil, errors.New("context must be non-nil")
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := c.send(ctx, req)
 	if err != nil {
+		// If the context has been canceled, its error is probably more useful.
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
 		return nil, err
 	}
 
@@ -150,11 +190,42 @@ func (c *Client) Do(req *http.Request, v interface{}) (*Response, error) {
 	return response, err
 }
 
+// send performs the HTTP round trip, retrying transient failures according to the client's RetryPolicy.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	p := c.RetryPolicy
+	if p == nil || p.MaxAttempts < 2 || !retryAllowed(ctx, req) {
+		if err := c.throttle(ctx, req); err != nil {
+			return nil, err
+		}
+		return c.client.Do(req)
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := c.throttle(ctx, req); err != nil {
+			return nil, err
+		}
+		resp, err := c.client.Do(req)
+		if attempt >= p.MaxAttempts || ctx.Err() != nil || !retryable(req, resp, err) {
+			return resp, err
+		}
+
+		wait := p.backoff(attempt, resp)
+		discard(resp)
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		if req, err = rewind(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
 // Errors returned by the Plivo API.
 type ErrorResponse struct {
 	Response *http.Response
-	Message  string  `json:"message"`
-	Errors   []Error `json:"errors"`
+	ApiID    string  `json:"api_id"`
+	Message  string  `json:"message"` // The "error" (or "message") field of the response.
+	Errors   []Error `json:"errors"`  // Per-field validation errors, if any.
 }
 
 // Fetches the string representation of an ErrorResponse.
@@ -164,6 +235,42 @@ func (r *ErrorResponse) Error() string {
 		r.Response.StatusCode, r.Message, r.Errors)
 }
 
+// UnmarshalJSON decodes an API error body. Plivo reports the error either as
+// a string or as an object mapping field names to messages.
+func (r *ErrorResponse) UnmarshalJSON(data []byte) error {
+	var body struct {
+		ApiID   string          `json:"api_id"`
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+		Errors  []Error         `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return err
+	}
+	r.ApiID, r.Message, r.Errors = body.ApiID, body.Message, body.Errors
+
+	var msg string
+	var fields map[string]interface{}
+	switch {
+	case len(body.Error) == 0:
+	case json.Unmarshal(body.Error, &msg) == nil:
+		r.Message = msg
+	case json.Unmarshal(body.Error, &fields) == nil:
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			r.Errors = append(r.Errors, Error{Field: name, Code: fmt.Sprint(fields[name])})
+		}
+		if r.Message == "" {
+			r.Message = "validation failed"
+		}
+	}
+	return nil
+}
+
 // Error type contains more details about the error.
 type Error struct {
 	Resource string `json:"resource"` // Resource on which the error was generated.
@@ -185,14 +292,11 @@ func checkResponse(r *http.Response) error {
 	}
 	errorResponse := &ErrorResponse{Response: r}
 	data, err := ioutil.ReadAll(r.Body)
-	if err != nil && data != nil {
-		json.Unmarshal(data, errorResponse)
+	if err == nil && len(data) > 0 {
+		if json.Unmarshal(data, errorResponse) != nil {
+			// Not JSON, typically an error page from a proxy.
+			errorResponse.Message = strings.TrimSpace(string(data))
+		}
 	}
 	return errorResponse
 }
-
-// limitOffset is a utility type for handling offsets in the API calls.
-type limitOffset struct {
-	limit  int64
-	offset int64
-}
@@ -1,9 +1,19 @@
 package plivo
 
 import (
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -11,6 +21,7 @@ import (
 var (
 	// client is the API client being tested.
 	client      *Client
+	ctx         = context.Background()
 	currTime    int64
 	testAccount string
 	config      map[string]string
@@ -49,7 +60,7 @@ func setup() {
 func TestAccountGet(t *testing.T) {
 	setup()
 	client = NewClient(nil, authID, authToken)
-	acc, _, err := client.Account.Get()
+	acc, _, err := client.Account.Get(ctx)
 	if err != nil {
 		t.Errorf("AccountGet failed: %v", err)
 	} else {
@@ -61,7 +72,7 @@ func TestAccountModify(t *testing.T) {
 	setup()
 	client = NewClient(nil, authID, authToken)
 	acc := &Account{Name: "Test Name", City: "Test City", Address: "Test Address", AuthID: authID}
-	acc, _, err := client.Account.Modify(acc)
+	acc, _, err := client.Account.Modify(ctx, acc)
 	if err != nil {
 		t.Errorf("AccountModify failed: %v", err)
 	}
@@ -72,7 +83,7 @@ func TestAccountCreateSubaccount(t *testing.T) {
 	setup()
 	client = NewClient(nil, authID, authToken)
 	sacc := &Subaccount{Name: testAccount, Enabled: false}
-	_, err := client.Account.CreateSubaccount(sacc)
+	_, err := client.Account.CreateSubaccount(ctx, sacc)
 	if err != nil {
 		t.Errorf("AccountCreateSubaccount failed: %v", err)
 	} else {
@@ -84,12 +95,12 @@ func TestAccountModifySubaccount(t *testing.T) {
 	setup()
 	client = NewClient(nil, authID, authToken)
 	sacc := &Subaccount{Name: testAccount + "_mod", Enabled: false}
-	_, err := client.Account.CreateSubaccount(sacc)
+	_, err := client.Account.CreateSubaccount(ctx, sacc)
 	if err != nil {
 		t.Errorf("TestAccountModifySubaccount failed at account creation: %v", err)
 	}
 	sacc.Enabled = true
-	sacc, _, err = client.Account.ModifySubaccount(sacc)
+	sacc, _, err = client.Account.ModifySubaccount(ctx, sacc)
 	if err != nil {
 		t.Errorf("AccountModifySubaccount failed at account modification: %v", err)
 	} else {
@@ -102,12 +113,12 @@ func TestAccountGetSubaccount(t *testing.T) {
 	client = NewClient(nil, authID, authToken)
 
 	sacc := &Subaccount{Name: testAccount + "_get", Enabled: false}
-	_, err := client.Account.CreateSubaccount(sacc)
+	_, err := client.Account.CreateSubaccount(ctx, sacc)
 	if err != nil {
 		t.Errorf("TestAccountGetSubaccount failed at account creation: %v", err)
 	}
 
-	sacc, _, err = client.Account.GetSubaccount(sacc.AuthID)
+	sacc, _, err = client.Account.GetSubaccount(ctx, sacc.AuthID)
 	if err != nil {
 		t.Errorf("AccountGetSubaccount failed: %v", err)
 	} else {
@@ -121,12 +132,12 @@ func TestAccountGetSubaccounts(t *testing.T) {
 
 	for i := 0; i < 2; i++ {
 		sacc := &Subaccount{Name: testAccount + fmt.Sprintf("_get_mult_%d", i), Enabled: false}
-		_, err := client.Account.CreateSubaccount(sacc)
+		_, err := client.Account.CreateSubaccount(ctx, sacc)
 		if err != nil {
 			t.Errorf("TestAccountGetSubaccounts failed at account creation: %v", err)
 		}
 	}
-	sacc, _, err := client.Account.GetSubaccounts(0, 0)
+	sacc, _, err := client.Account.GetSubaccounts(ctx, 0, 0)
 	if err != nil {
 		t.Errorf("AccountGetSubaccounts failed: %v", err)
 	} else {
@@ -139,12 +150,12 @@ func TestAccountDeleteSubaccount(t *testing.T) {
 	client = NewClient(nil, authID, authToken)
 
 	sacc := &Subaccount{Name: testAccount + "_del", Enabled: false}
-	_, err := client.Account.CreateSubaccount(sacc)
+	_, err := client.Account.CreateSubaccount(ctx, sacc)
 	if err != nil {
 		t.Errorf("TestAccountDeleteSubaccount failed at account creation: %v", err)
 	}
 
-	_, err = client.Account.DeleteSubaccount(sacc.AuthID)
+	_, err = client.Account.DeleteSubaccount(ctx, sacc.AuthID)
 	if err != nil {
 		t.Errorf("AccountDeleteSubaccount failed: %v", err)
 	} else {
@@ -156,7 +167,7 @@ func TestApplicationCreate(t *testing.T) {
 	setup()
 	client = NewClient(nil, authID, authToken)
 	app := &Application{AnswerURL: AnswerURL, AppName: "Test App (Create)"}
-	app, _, err := client.Application.Create(app)
+	app, _, err := client.Application.Create(ctx, app)
 	if err != nil {
 		t.Errorf("ApplicationCreate failed: %v", err)
 	}
@@ -166,7 +177,7 @@ func TestApplicationCreate(t *testing.T) {
 func TestApplicationGetApplications(t *testing.T) {
 	setup()
 	client = NewClient(nil, authID, authToken)
-	apps, _, err := client.Application.GetApplications(0, 0)
+	apps, _, err := client.Application.GetApplications(ctx, 0, 0)
 	if err != nil {
 		t.Errorf("ApplicationGetApplications failed: %v", err)
 	} else {
@@ -179,12 +190,12 @@ func TestApplicationGet(t *testing.T) {
 	client = NewClient(nil, authID, authToken)
 
 	app := &Application{AnswerURL: "http://example.com/answer/", AppName: "Test App (Get)"}
-	app, _, err := client.Application.Create(app)
+	app, _, err := client.Application.Create(ctx, app)
 	if err != nil {
 		t.Errorf("ApplicationGet failed at application creation: %v", err)
 	}
 
-	app, _, err = client.Application.Get(app.AppID)
+	app, _, err = client.Application.Get(ctx, app.AppID)
 	if err != nil {
 		t.Errorf("ApplicationGet failed: %v", err)
 	} else {
@@ -197,12 +208,12 @@ func TestApplicationDelete(t *testing.T) {
 	client = NewClient(nil, authID, authToken)
 
 	app := &Application{AnswerURL: AnswerURL, AppName: "Test App (Delete)"}
-	app, _, err := client.Application.Create(app)
+	app, _, err := client.Application.Create(ctx, app)
 	if err != nil {
 		t.Errorf("ApplicationDelete failed at application creation: %v", err)
 	}
 
-	_, err = client.Application.Delete(app.AppID)
+	_, err = client.Application.Delete(ctx, app.AppID)
 	if err != nil {
 		t.Errorf("ApplicationDelete failed: %v", err)
 	} else {
@@ -213,8 +224,8 @@ func TestApplicationDelete(t *testing.T) {
 func TestCallMake(t *testing.T) {
 	setup()
 	client = NewClient(nil, authID, authToken)
-	cp := &CallMakeParams{From: FromNumber, To: ToNumber, AnswerURL: AnswerURL}
-	_, err := client.Call.Make(cp)
+	cp := &CallMakeParams{From: PhoneNumber(FromNumber), To: PhoneNumber(ToNumber), AnswerURL: AnswerURL}
+	_, _, err := client.Call.Make(ctx, cp)
 	if err != nil {
 		t.Errorf("CallMake failed: %v", err)
 	}
@@ -225,7 +236,7 @@ func TestCallGetAll(t *testing.T) {
 	setup()
 	client = NewClient(nil, authID, authToken)
 	cp := &CallGetAllParams{}
-	calls, _, err := client.Call.GetAll(cp)
+	calls, _, err := client.Call.GetAll(ctx, cp)
 	if err != nil {
 		t.Errorf("CallGetAll failed: %v", err)
 	}
@@ -237,7 +248,7 @@ func TestCallGetAll(t *testing.T) {
 // 	setup()
 // 	client = NewClient(nil, authID, authToken)
 // 	c := &Call{CallUUID: ""}
-// 	call, _, err := client.Call.Get(c.CallUUID)
+// 	call, _, err := client.Call.Get(ctx, c.CallUUID)
 // 	if err != nil {
 // 		t.Errorf("CallGet failed: %v", err)
 // 	}
@@ -248,9 +259,949 @@ func TestCallGetAll(t *testing.T) {
 // func TestCallGetAllLive(t *testing.T) {
 // 	setup()
 // 	client = NewClient(nil, authID, authToken)
-// 	calls, _, err := client.Call.GetAllLive()
+// 	calls, _, err := client.Call.GetAllLive(ctx)
 // 	if err != nil {
 // 		t.Errorf("CallGetAllLive failed: %v", err)
 // 	}
 // 	t.Logf("Calls: %v\n", calls)
 // }
+
+func TestDoContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client = newTestClient(server)
+
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, _, err := client.Account.Get(cctx)
+	if err != context.DeadlineExceeded {
+		t.Errorf("AccountGet returned %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+// newTestClient returns a client which talks to server instead of the live API.
+func newTestClient(server *httptest.Server) *Client {
+	c := NewClient(nil, "MAXXXXXXXXXXXXXXXXXX", "token")
+	c.BaseURL, _ = url.Parse(server.URL + "/")
+	c.RetryPolicy.MinBackoff = time.Millisecond
+	c.RetryPolicy.MaxBackoff = 5 * time.Millisecond
+	return c
+}
+
+func TestDoRetry(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"auth_id": "MAXXXXXXXXXXXXXXXXXX"}`)
+	}))
+	defer server.Close()
+
+	client = newTestClient(server)
+	acc, _, err := client.Account.Get(ctx)
+	if err != nil {
+		t.Fatalf("AccountGet failed: %v", err)
+	}
+	if attempts != 3 || acc.AuthID != "MAXXXXXXXXXXXXXXXXXX" {
+		t.Errorf("AccountGet made %d attempts and returned %+v", attempts, acc)
+	}
+}
+
+func TestDoRetryPOST(t *testing.T) {
+	attempts := 0
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client = newTestClient(server)
+	cp := &CallMakeParams{From: "14155550100", To: "14155550101", AnswerURL: "http://example.com/"}
+	if _, _, err := client.Call.Make(ctx, cp); err == nil {
+		t.Errorf("CallMake did not fail")
+	}
+	if attempts != 1 {
+		t.Errorf("CallMake made %d attempts without WithRetry, want 1", attempts)
+	}
+
+	attempts, bodies = 0, nil
+	if _, _, err := client.Call.Make(WithRetry(ctx), cp); err == nil {
+		t.Errorf("CallMake did not fail")
+	}
+	if attempts != client.RetryPolicy.MaxAttempts {
+		t.Errorf("CallMake made %d attempts with WithRetry, want %d", attempts, client.RetryPolicy.MaxAttempts)
+	}
+	for _, b := range bodies {
+		if b != bodies[0] || b == "" {
+			t.Errorf("retried body %q differs from %q", b, bodies[0])
+		}
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestDoRetryTransportErrors(t *testing.T) {
+	timeout := &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}
+	for _, tt := range []struct {
+		name     string
+		err      error
+		post     bool
+		attempts int
+	}{
+		{"refused GET", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, false, 3},
+		{"refused POST", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true, 3},
+		{"reset GET", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, false, 3},
+		{"reset POST", &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, true, 1},
+		{"timeout GET", timeout, false, 3},
+		{"timeout POST", timeout, true, 1},
+		{"TLS GET", errors.New("tls: failed to verify certificate"), false, 1},
+	} {
+		attempts := 0
+		c := NewClient(&http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			attempts++
+			return nil, tt.err
+		})}, "MAXXXXXXXXXXXXXXXXXX", "token")
+		c.RetryPolicy.MinBackoff = time.Millisecond
+		var err error
+		if tt.post {
+			_, _, err = c.Call.Make(WithRetry(ctx), &CallMakeParams{From: "14155550100", To: "14155550101", AnswerURL: "http://example.com/"})
+		} else {
+			_, _, err = c.Account.Get(ctx)
+		}
+		if err == nil || attempts != tt.attempts {
+			t.Errorf("%s: made %d attempts, want %d; err %v", tt.name, attempts, tt.attempts, err)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := &RetryPolicy{MinBackoff: time.Second, MaxBackoff: 10 * time.Second, RespectRetryAfter: true}
+	resp := &http.Response{Header: http.Header{"Retry-After": {"3600"}}}
+	if d := p.backoff(1, resp); d != p.MaxBackoff {
+		t.Errorf("backoff with Retry-After: 3600 = %v, want %v", d, p.MaxBackoff)
+	}
+	resp.Header.Set("Retry-After", "2")
+	if d := p.backoff(1, resp); d != 2*time.Second {
+		t.Errorf("backoff with Retry-After: 2 = %v, want 2s", d)
+	}
+	if d := p.backoff(5, nil); d != p.MaxBackoff {
+		t.Errorf("backoff(5) = %v, want %v", d, p.MaxBackoff)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d, ok := parseRetryAfter("3"); !ok || d != 3*time.Second {
+		t.Errorf("parseRetryAfter(3) = %v, %v", d, ok)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if d, ok := parseRetryAfter(date); !ok || d < 59*time.Minute {
+		t.Errorf("parseRetryAfter(%q) = %v, %v", date, d, ok)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Errorf("parseRetryAfter(soon) succeeded")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(10, 2)
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, ok := l.reserve(now, false); !ok {
+			t.Fatalf("reserve %d failed within burst", i)
+		}
+	}
+	if _, ok := l.reserve(now, false); ok {
+		t.Errorf("reserve succeeded with an empty bucket")
+	}
+	if d, ok := l.reserve(now, true); !ok || d != 100*time.Millisecond {
+		t.Errorf("reserve returned wait %v, %v; want 100ms", d, ok)
+	}
+	if _, ok := l.reserve(now.Add(300*time.Millisecond), false); !ok {
+		t.Errorf("reserve failed after refill")
+	}
+}
+
+func TestClientCallLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server)
+	client.Limiter = NewRateLimiter(0.001, 2)
+	client.CallLimiter = NewRateLimiter(0.001, 1)
+	cp := &CallMakeParams{From: "14155550100", To: "14155550101", AnswerURL: "http://example.com/"}
+	if _, _, err := client.Call.Make(WithFailFast(ctx), cp); err != nil {
+		t.Fatalf("first CallMake failed: %v", err)
+	}
+	if _, _, err := client.Call.Make(WithFailFast(ctx), cp); err != ErrRateLimited {
+		t.Errorf("second CallMake returned %v, want ErrRateLimited", err)
+	}
+	if _, _, err := client.Message.Send(WithFailFast(ctx), &MessageSendParams{}); err != nil {
+		t.Errorf("MessageSend was limited by CallLimiter: %v", err)
+	}
+	// The refused second call gave back its token to the global limiter,
+	// which MessageSend used; none is left.
+	if client.Limiter.Allow() {
+		t.Errorf("global limiter has tokens left after two requests")
+	}
+}
+
+// pagesOf returns a PageFunc serving the integers [0, n) and counting its calls.
+func pagesOf(n int64, calls *int) PageFunc[int64] {
+	return func(ctx context.Context, limit, offset int64) ([]int64, *Meta, error) {
+		*calls++
+		var items []int64
+		for i := offset; i < n && i < offset+limit; i++ {
+			items = append(items, i)
+		}
+		meta := &Meta{Limit: limit, Offset: offset, TotalCount: n}
+		if offset+limit < n {
+			meta.Next = "next"
+		}
+		return items, meta, nil
+	}
+}
+
+func TestPager(t *testing.T) {
+	for _, prefetch := range []bool{false, true} {
+		calls := 0
+		p := NewPager(ctx, 0, pagesOf(45, &calls))
+		p.Prefetch = prefetch
+		var got int64
+		for p.Next() {
+			if p.Value() != got {
+				t.Fatalf("Value() = %d, want %d", p.Value(), got)
+			}
+			got++
+		}
+		if p.Err() != nil || got != 45 || calls != 3 {
+			t.Errorf("prefetch=%v: got %d items in %d pages, err %v", prefetch, got, calls, p.Err())
+		}
+	}
+}
+
+func TestPagerMaxItems(t *testing.T) {
+	calls := 0
+	p := NewPager(ctx, 0, pagesOf(100, &calls))
+	p.PageSize = 10
+	p.MaxItems = 25
+	got := 0
+	for p.Next() {
+		got++
+	}
+	if got != 25 || calls != 3 {
+		t.Errorf("got %d items in %d pages, want 25 in 3", got, calls)
+	}
+}
+
+func TestPagerPageSizeCap(t *testing.T) {
+	// Like the API, return at most 20 objects, without pagination metadata.
+	var limits []int64
+	p := NewPager(ctx, 0, func(ctx context.Context, limit, offset int64) ([]int64, *Meta, error) {
+		limits = append(limits, limit)
+		calls := 0
+		items, _, err := pagesOf(45, &calls)(ctx, min(limit, 20), offset)
+		return items, nil, err
+	})
+	p.PageSize = 50
+	var got int64
+	for p.Next() {
+		if p.Value() != got {
+			t.Fatalf("Value() = %d, want %d", p.Value(), got)
+		}
+		got++
+	}
+	if got != 45 || fmt.Sprint(limits) != "[20 20 20]" {
+		t.Errorf("got %d items with limits %v, want 45 with [20 20 20]", got, limits)
+	}
+}
+
+func TestPagerErr(t *testing.T) {
+	boom := fmt.Errorf("boom")
+	p := NewPager(ctx, 0, func(ctx context.Context, limit, offset int64) ([]int64, *Meta, error) {
+		return nil, nil, boom
+	})
+	if p.Next() || p.Err() != boom {
+		t.Errorf("Next did not stop on error, Err() = %v", p.Err())
+	}
+}
+
+func TestSignatureValidator(t *testing.T) {
+	const token = "token"
+	form := url.Values{"CallUUID": {"abc"}, "From": {"14155550100"}}
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest("POST", "http://example.com/answer/?x=1", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+	v := NewSignatureValidator(token)
+
+	// A V1 signature has no nonce: replaying a captured request with its V2
+	// and V3 headers removed must fail unless V1 is allowed.
+	r := newRequest()
+	r.Header.Set("X-Plivo-Signature", signatureV1(token, "http://example.com/answer/?x=1", form))
+	if err := v.Validate(r); err != ErrInvalidSignature {
+		t.Errorf("V1 Validate returned %v, want ErrInvalidSignature", err)
+	}
+	v.AllowV1 = true
+	if err := v.Validate(r); err != nil {
+		t.Errorf("V1 Validate with AllowV1 failed: %v", err)
+	}
+	v.AllowV1 = false
+
+	r = newRequest()
+	r.Header.Set("X-Plivo-Signature-V2", signatureV2(token, "http://example.com/answer/", "n2"))
+	r.Header.Set("X-Plivo-Signature-V2-Nonce", "n2")
+	if err := v.Validate(r); err != nil {
+		t.Errorf("V2 Validate failed: %v", err)
+	}
+
+	sig := signatureV3(token, "POST", "http://example.com/answer/?x=1", "n3", form)
+	r = newRequest()
+	r.Header.Set("X-Plivo-Signature-V3", "bogus, "+sig)
+	r.Header.Set("X-Plivo-Signature-V3-Nonce", "n3")
+	if err := v.Validate(r); err != nil {
+		t.Errorf("V3 Validate failed: %v", err)
+	}
+	if r.PostForm.Get("CallUUID") != "abc" {
+		t.Errorf("PostForm not available after Validate")
+	}
+
+	r = newRequest()
+	r.Header.Set("X-Plivo-Signature-V3", sig)
+	r.Header.Set("X-Plivo-Signature-V3-Nonce", "n3")
+	if err := v.Validate(r); err != ErrReplayedRequest {
+		t.Errorf("replayed Validate returned %v, want ErrReplayedRequest", err)
+	}
+
+	// Expired nonces are forgotten.
+	v.ReplayWindow = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	if err := v.checkNonce("n5"); err != nil || len(v.seen) != 1 || len(v.order) != 1 {
+		t.Errorf("checkNonce kept %d nonces, err %v", len(v.seen), err)
+	}
+	v.ReplayWindow = 0
+
+	r = newRequest()
+	r.Header.Set("X-Plivo-Signature-V3", signatureV3("other", "POST", "http://example.com/answer/?x=1", "n4", form))
+	r.Header.Set("X-Plivo-Signature-V3-Nonce", "n4")
+	w := httptest.NewRecorder()
+	v.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Middleware passed a forged request")
+	})).ServeHTTP(w, r)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Middleware returned %d, want 403", w.Code)
+	}
+}
+
+func TestParseCallback(t *testing.T) {
+	form := url.Values{
+		"CallUUID": {"abc"}, "From": {"14155550100"}, "Direction": {"outbound"},
+		"CallStatus": {"completed"}, "HangupCause": {"NORMAL_CLEARING"},
+		"Duration": {"42"}, "BillDuration": {"60"}, "Machine": {"false"},
+		"EndTime": {"2014-03-23 16:56:23"},
+	}
+	r := httptest.NewRequest("POST", "http://example.com/hangup/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var h HangupCallback
+	if err := ParseCallback(r, &h); err != nil {
+		t.Fatalf("ParseCallback failed: %v", err)
+	}
+	if h.CallUUID != "abc" || h.HangupCause != "NORMAL_CLEARING" || h.Duration != 42 || h.BillDuration != 60 || h.EndTime.Hour() != 16 {
+		t.Errorf("ParseCallback(form) = %+v", h)
+	}
+
+	body := `{"CallUUID": "def", "To": "14155550101", "Machine": true, "Duration": 7}`
+	r = httptest.NewRequest("POST", "http://example.com/answer/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	var a AnswerCallback
+	if err := ParseCallback(r, &a); err != nil {
+		t.Fatalf("ParseCallback failed: %v", err)
+	}
+	if a.CallUUID != "def" || a.To != "14155550101" || !a.Machine {
+		t.Errorf("ParseCallback(json) = %+v", a)
+	}
+
+	r = httptest.NewRequest("POST", "http://example.com/hangup/", strings.NewReader("Duration=soon"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if err := ParseCallback(r, &h); err == nil {
+		t.Errorf("ParseCallback accepted a malformed Duration")
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	tests := []struct {
+		code    int
+		body    string
+		message string
+		is      func(error) bool
+	}{
+		{404, `{"api_id": "a1", "error": "not found"}`, "not found", IsNotFound},
+		{401, `{"api_id": "a1", "error": "authentication failed"}`, "authentication failed", IsUnauthorized},
+		{429, `{"api_id": "a1", "error": "too many requests"}`, "too many requests", IsRateLimited},
+		{400, `{"api_id": "a1", "error": {"to": ["invalid number"]}}`, "validation failed", IsValidation},
+		{502, `<html>Bad Gateway</html>`, "<html>Bad Gateway</html>", IsServerError},
+	}
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.code)
+			fmt.Fprint(w, tt.body)
+		}))
+		client = newTestClient(server)
+		client.RetryPolicy = nil
+		_, _, err := client.Account.Get(ctx)
+		server.Close()
+
+		var er *ErrorResponse
+		if !errors.As(err, &er) {
+			t.Fatalf("%d: AccountGet returned %T, want *ErrorResponse", tt.code, err)
+		}
+		if er.Message != tt.message || (strings.HasPrefix(tt.body, "{") && er.ApiID != "a1") {
+			t.Errorf("%d: ErrorResponse = %+v", tt.code, er)
+		}
+		if !tt.is(err) {
+			t.Errorf("%d: error not classified: %v", tt.code, err)
+		}
+		if tt.code != 404 && IsNotFound(err) {
+			t.Errorf("%d: error classified as not found", tt.code)
+		}
+	}
+}
+
+func TestEncodeQuery(t *testing.T) {
+	tests := []struct {
+		params interface{}
+		want   string
+	}{
+		{&CallGetAllParams{Subaccount: "SA1", CallDirection: Outbound, FromNumber: "14155550100", ToNumber: "14155550101",
+			EndTime: TimeFilter{Eq: time.Date(2014, 1, 1, 0, 0, 0, 0, time.UTC)}, BillDuration: IntFilter{Eq: 60}, Limit: 20, Offset: 40},
+			"bill_duration=60&call_direction=outbound&end_time=2014-01-01+00%3A00%3A00&from_number=14155550100&limit=20&offset=40&subaccount=SA1&to_number=14155550101"},
+		{&CallGetAllParams{}, ""},
+		{&MessageGetAllParams{Limit: 5, Offset: 10}, "limit=5&offset=10"},
+		{&NumberGetAllParams{NumberType: "local", NumberStartswith: "1415", Subaccount: "SA1", Services: "voice", Limit: 5},
+			"limit=5&number_startswith=1415&number_type=local&services=voice&subaccount=SA1"},
+		{&NumberSearchParams{CountryISO: "US", NumberType: "tollfree", Prefix: "800", Region: "CA", Services: "sms", Limit: 3, Offset: 6},
+			"country_iso=US&limit=3&number_type=tollfree&offset=6&prefix=800&region=CA&services=sms"},
+		{&RecordingGetAllParams{Subaccount: "SA1", CallUUID: "abc", Limit: 1, Offset: 2},
+			"call_uuid=abc&limit=1&offset=2&subaccount=SA1"},
+		{&IncomingCarrierGetAllParams{Name: "in", Limit: 1, Offset: 2}, "limit=1&name=in&offset=2"},
+		{&OutgoingCarrierGetAllParams{Name: "out", Limit: 1, Offset: 2}, "limit=1&name=out&offset=2"},
+		{&PricingGetParams{CountryISO: "GB"}, "country_iso=GB"},
+		{&limitOffset{20, 40}, "limit=20&offset=40"},
+		{(*CallGetAllParams)(nil), ""},
+	}
+	for _, tt := range tests {
+		got, err := encodeQuery(url.Values{}, tt.params)
+		if err != nil {
+			t.Errorf("encodeQuery(%T) failed: %v", tt.params, err)
+		} else if got != tt.want {
+			t.Errorf("encodeQuery(%T) = %q, want %q", tt.params, got, tt.want)
+		}
+	}
+}
+
+func TestNewRequestQuery(t *testing.T) {
+	client = NewClient(nil, "MAXXXXXXXXXXXXXXXXXX", "token")
+	req, err := client.NewRequest("GET", "MAXXXXXXXXXXXXXXXXXX/Call/?status=live", &limitOffset{Limit: 20})
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+	if got := req.URL.RawQuery; got != "limit=20&status=live" {
+		t.Errorf("NewRequest query = %q", got)
+	}
+}
+
+func TestFilters(t *testing.T) {
+	day := time.Date(2014, 3, 1, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+	p := &CallGetAllParams{
+		CallDirection: Outbound,
+		EndTime:       TimeBetween(day, day.AddDate(0, 0, 1)),
+		BillDuration:  IntFilter{Gt: 60, Lte: 0},
+	}
+	got, err := encodeQuery(url.Values{}, p)
+	if err != nil {
+		t.Fatalf("encodeQuery failed: %v", err)
+	}
+	want := "bill_duration__gt=60&bill_duration__lte=0&call_direction=outbound" +
+		"&end_time__gte=2014-02-28+23%3A00%3A00&end_time__lt=2014-03-01+23%3A00%3A00"
+	if got != want {
+		t.Errorf("encodeQuery = %q, want %q", got, want)
+	}
+
+	got, _ = encodeQuery(url.Values{}, &RecordingGetAllParams{AddTime: TimeFilter{Lt: day}})
+	if want := "add_time__lt=2014-02-28+23%3A00%3A00"; got != want {
+		t.Errorf("encodeQuery = %q, want %q", got, want)
+	}
+}
+
+func TestTime(t *testing.T) {
+	var c Call
+	if err := json.Unmarshal([]byte(`{"end_time": "2014-03-23 16:56:23-07:00"}`), &c); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	want := time.Date(2014, 3, 23, 23, 56, 23, 0, time.UTC)
+	if !c.EndTime.Equal(want) {
+		t.Errorf("EndTime = %v, want %v", c.EndTime, want)
+	}
+	b, _ := json.Marshal(c.EndTime)
+	if string(b) != `"2014-03-23 16:56:23-07:00"` {
+		t.Errorf("Marshal = %s", b)
+	}
+
+	// Text, XML and query encodings use the same layout and round-trip.
+	text, err := c.EndTime.MarshalText()
+	if err != nil || string(text) != "2014-03-23 16:56:23-07:00" {
+		t.Errorf("MarshalText = %s, %v", text, err)
+	}
+	var back Time
+	if err := back.UnmarshalText(text); err != nil || !back.Equal(c.EndTime.Time) {
+		t.Errorf("UnmarshalText(%s) = %v, %v", text, back, err)
+	}
+	x, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"call"`
+		End     Time     `xml:"end,attr"`
+	}{End: c.EndTime})
+	if err != nil || string(x) != `<call end="2014-03-23 16:56:23-07:00"></call>` {
+		t.Errorf("xml.Marshal = %s, %v", x, err)
+	}
+	q, err := encodeQuery(url.Values{}, struct {
+		End   Time `url:"end_time,omitempty"`
+		Start Time `url:"start_time,omitempty"`
+	}{End: c.EndTime})
+	if err != nil || q != "end_time=2014-03-23+16%3A56%3A23-07%3A00" {
+		t.Errorf("encodeQuery = %s, %v", q, err)
+	}
+
+	for _, s := range []string{"2014-03-23 16:56:23.470000-07:00", "2014-03-23 23:56:23", "2014-03-23"} {
+		if _, err := ParseTime(s); err != nil {
+			t.Errorf("ParseTime(%q) failed: %v", s, err)
+		}
+	}
+	if _, err := ParseTime("yesterday"); err == nil {
+		t.Errorf("ParseTime accepted garbage")
+	}
+
+	var a Account
+	if err := json.Unmarshal([]byte(`{"created": "2012-03-21", "modified": null}`), &a); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if a.Created.Year() != 2012 || !a.Modified.IsZero() {
+		t.Errorf("Account = %v, %v", a.Created, a.Modified)
+	}
+
+	var r Recording
+	if err := json.Unmarshal([]byte(`{"recording_start_ms": "1395619200123", "recording_end_ms": 1395619260000}`), &r); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if r.RecordingStartMS.Millis() != 1395619200123 || r.RecordingEndMS.Sub(r.RecordingStartMS.Time) != 59877*time.Millisecond {
+		t.Errorf("Recording = %v, %v", r.RecordingStartMS, r.RecordingEndMS)
+	}
+}
+
+func TestAccountRequestBodies(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, strings.TrimSpace(string(b)))
+		fmt.Fprint(w, `{"message": "ok"}`)
+	}))
+	defer server.Close()
+	client := newTestClient(server)
+
+	// Read-only timestamps and unset amounts are left out of request bodies.
+	if _, _, err := client.Account.Modify(ctx, &Account{Name: "renamed", City: "London"}); err != nil {
+		t.Fatalf("Modify failed: %v", err)
+	}
+	if _, err := client.Account.CreateSubaccount(ctx, &Subaccount{Name: "sub", Enabled: true}); err != nil {
+		t.Fatalf("CreateSubaccount failed: %v", err)
+	}
+	if _, _, err := client.Account.ModifySubaccount(ctx, &Subaccount{AuthID: "SA1", Name: "renamed"}); err != nil {
+		t.Fatalf("ModifySubaccount failed: %v", err)
+	}
+	want := []string{
+		`{"city":"London","name":"renamed"}`,
+		`{"name":"sub","enabled":true}`,
+		`{"auth_id":"SA1","name":"renamed"}`,
+	}
+	if strings.Join(bodies, "\n") != strings.Join(want, "\n") {
+		t.Errorf("bodies:\n%s\nwant:\n%s", strings.Join(bodies, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestMoney(t *testing.T) {
+	for in, want := range map[string]string{
+		"0.00350":    "0.0035",
+		"-12":        "-12",
+		"+1.5":       "1.5",
+		".25":        "0.25",
+		"100.00000":  "100",
+		"0.00000001": "0.00000001",
+	} {
+		m, err := ParseMoney(in)
+		if err != nil {
+			t.Errorf("ParseMoney(%q) failed: %v", in, err)
+			continue
+		}
+		if m.String() != want {
+			t.Errorf("ParseMoney(%q) = %s, want %s", in, m, want)
+		}
+	}
+	for _, in := range []string{"", "-", "1.2.3", "abc", "0.0000000001", "99999999999"} {
+		if _, err := ParseMoney(in); err == nil {
+			t.Errorf("ParseMoney(%q) succeeded", in)
+		}
+	}
+
+	rate := MustParseMoney("0.0035")
+	if got := rate.Mul(3).Add(MustParseMoney("0.1")); got.String() != "0.1105" {
+		t.Errorf("Mul/Add = %s", got)
+	}
+	if got := MustParseMoney("0.01").MulDuration(90*time.Second, time.Minute); got.String() != "0.015" {
+		t.Errorf("MulDuration = %s", got)
+	}
+	if got := MustParseMoney("0.1").MulDuration(time.Second, 3*time.Second); got.String() != "0.033333333" {
+		t.Errorf("MulDuration rounding = %s", got)
+	}
+	if got := MustParseMoney("0.0035").StringFixed(2); got != "0.00" {
+		t.Errorf("StringFixed(2) = %s", got)
+	}
+	if got := MustParseMoney("-1.005").StringFixed(2); got != "-1.01" {
+		t.Errorf("StringFixed(2) = %s", got)
+	}
+	if got := rate.StringFixed(5); got != "0.00350" {
+		t.Errorf("StringFixed(5) = %s", got)
+	}
+
+	// Arithmetic saturates instead of wrapping around.
+	huge := MustParseMoney("9000000000")
+	for name, got := range map[string]Money{
+		"Add":         huge.Add(huge),
+		"Sub":         huge.Sub(MustParseMoney("-9000000000")),
+		"Mul":         rate.Mul(1 << 62),
+		"MulDuration": huge.MulDuration(time.Hour, time.Second),
+	} {
+		if got != MaxMoney {
+			t.Errorf("%s = %s, want MaxMoney", name, got)
+		}
+	}
+	if got := MustParseMoney("-9000000000").Sub(huge); got != MinMoney {
+		t.Errorf("Sub = %s, want MinMoney", got)
+	}
+	if got := MinMoney.Add(MaxMoney); got.String() != "-0.000000001" {
+		t.Errorf("MinMoney + MaxMoney = %s", got)
+	}
+
+	var msg Message
+	if err := json.Unmarshal([]byte(`{"total_amount": "0.00700", "cloud_rate": 0.0035, "carrier_rate": null}`), &msg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if msg.TotalAmount.Cmp(rate.Mul(2)) != 0 || msg.CloudRate != rate || !msg.CarrierRate.IsZero() {
+		t.Errorf("Message = %v, %v, %v", msg.TotalAmount, msg.CloudRate, msg.CarrierRate)
+	}
+	b, _ := json.Marshal(msg.TotalAmount)
+	if string(b) != `"0.007"` {
+		t.Errorf("Marshal = %s", b)
+	}
+	for in, want := range map[string]string{`"1.5"`: "1.5", `1.5e-3`: "0.0015", `2E2`: "200", `"\u0031"`: "1", `""`: "0"} {
+		var m Money
+		if err := json.Unmarshal([]byte(in), &m); err != nil || m.String() != want {
+			t.Errorf("Unmarshal(%s) = %s, %v; want %s", in, m, err, want)
+		}
+	}
+	for _, in := range []string{`"1.5`, `1.5"`, `1e-10`, `1e10`, `"1e3"`, `0x10`} {
+		var m Money
+		if err := m.UnmarshalJSON([]byte(in)); err == nil {
+			t.Errorf("Unmarshal(%s) = %s, want error", in, m)
+		}
+	}
+
+	var a Account
+	if err := json.Unmarshal([]byte(`{"cash_credits": "100.00000", "plan": {"name": "Standard", "voice_rate": "0.00850", "monthly_cloud_credits": "0.00000"}}`), &a); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if a.CashCredits.String() != "100" || a.Plan.Name != "Standard" || a.Plan.VoiceRate.String() != "0.0085" {
+		t.Errorf("Account = %s, %+v", a.CashCredits, a.Plan)
+	}
+}
+
+func TestPhoneNumber(t *testing.T) {
+	for _, tt := range []struct {
+		in, region string
+		want       PhoneNumber
+	}{
+		{"+1 (415) 555-0100", "", "14155550100"},
+		{"14155550100", "", "14155550100"},
+		{"(415) 555-0100", "US", "14155550100"},
+		{"1-415-555-0100", "us", "14155550100"},
+		{"011 44 20 7946 0958", "US", "442079460958"},
+		{"020 7946 0958", "GB", "442079460958"},
+		{"0044 20 7946 0958", "FR", "442079460958"},
+		{"06 12 34 56 78", "FR", "33612345678"},
+		{"06 1234 5678", "IT", "390612345678"},
+		{"+91 98765 43210", "GB", "919876543210"},
+	} {
+		got, err := ParsePhoneNumber(tt.in, tt.region)
+		if err != nil || got != tt.want {
+			t.Errorf("ParsePhoneNumber(%q, %q) = %q, %v; want %q", tt.in, tt.region, got, err, tt.want)
+		}
+	}
+	for _, tt := range [][2]string{
+		{"555-0100", ""},
+		{"415 555 0100 ext 2", "US"},
+		{"+1 415 555 01000", ""},
+		{"+999 1234 5678", ""},
+		{"0612345678", "XX"},
+		{"sip:alice@example.com", ""},
+	} {
+		if n, err := ParsePhoneNumber(tt[0], tt[1]); err == nil {
+			t.Errorf("ParsePhoneNumber(%q, %q) = %q, want error", tt[0], tt[1], n)
+		}
+	}
+
+	n := MustParsePhoneNumber("+44 20 7946 0958", "")
+	if n.CountryCode() != 44 || n.NationalNumber() != "2079460958" || n.E164() != "+442079460958" || !n.Valid() {
+		t.Errorf("PhoneNumber = %d, %s, %s, %v", n.CountryCode(), n.NationalNumber(), n.E164(), n.Valid())
+	}
+	if PhoneNumber("+14155550100").Valid() || PhoneNumber("sip:alice@example.com").Valid() {
+		t.Errorf("Valid accepted a non-E.164 number")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL)
+	}))
+	defer server.Close()
+	client := newTestClient(server)
+	if _, _, err := client.Call.Make(ctx, &CallMakeParams{From: "14155550100", To: "14155550101<415-555-0102", AnswerURL: "http://example.com/"}); err == nil {
+		t.Errorf("Make accepted a malformed destination")
+	}
+	if _, _, err := client.Message.Send(ctx, &MessageSendParams{Src: "ACME", Dst: "555", Text: "Hi"}); err == nil {
+		t.Errorf("Send accepted a malformed destination")
+	}
+	if _, _, err := client.Call.Make(ctx, nil); err == nil {
+		t.Errorf("Make accepted nil parameters")
+	}
+	if _, _, err := client.Message.Send(ctx, nil); err == nil {
+		t.Errorf("Send accepted nil parameters")
+	}
+}
+
+func TestCallState(t *testing.T) {
+	for _, tt := range []struct {
+		call Call
+		want CallState
+	}{
+		{Call{HangupCause: "Normal Hangup"}, CallCompleted},
+		{Call{HangupCause: "busy line"}, CallBusy},
+		{Call{HangupCause: "Ring Timeout Reached"}, CallNoAnswer},
+		{Call{HangupCause: "Cancelled"}, CallCancelled},
+		{Call{HangupCause: "Cancelled (Out Of Credits)"}, CallFailed},
+		{Call{HangupCause: "Machine Detected"}, CallMachine},
+		// Causes outside the documented list are decided by whether the call was answered.
+		{Call{HangupCause: "Invalid Busy Signal"}, CallFailed},
+		{Call{HangupCause: "Caller Hung Up", BillDuration: 60}, CallCompleted},
+		{Call{}, CallFailed},
+	} {
+		if got := tt.call.State(); got != tt.want {
+			t.Errorf("State(%q) = %s, want %s", tt.call.HangupCause, got, tt.want)
+		}
+	}
+}
+
+func TestWaitForCall(t *testing.T) {
+	pollMin, pollMax := callPollMin, callPollMax
+	t.Cleanup(func() { callPollMin, callPollMax = pollMin, pollMax })
+	callPollMin, callPollMax = time.Millisecond, 4*time.Millisecond
+	polls, queued := 0, false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls++
+		live := r.URL.Query().Get("status") == "live"
+		switch {
+		case polls <= 2 || queued:
+			// Queued: neither live nor ended.
+			w.WriteHeader(http.StatusNotFound)
+		case polls <= 6 && live:
+			fmt.Fprint(w, `{"call_uuid": "req-1", "call_status": "ringing"}`)
+		case live:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			fmt.Fprint(w, `{"call_uuid": "req-1", "hangup_cause_name": "No Answer"}`)
+		}
+	}))
+	defer server.Close()
+	client := newTestClient(server)
+
+	c, err := client.Call.WaitForCall(ctx, "req-1", CallRinging)
+	if err != nil || c.CallUUID != "req-1" || polls != 3 {
+		t.Errorf("WaitForCall(ringing) = %+v, %v after %d polls", c, err, polls)
+	}
+	c, err = client.Call.WaitForCall(ctx, "req-1")
+	if !errors.Is(err, ErrCallNoAnswer) || errors.Is(err, ErrCallBusy) || c.State() != CallNoAnswer {
+		t.Errorf("WaitForCall = %+v, %v; want ErrCallNoAnswer", c, err)
+	}
+	if _, err := client.Call.WaitForCall(ctx, "req-1", CallNoAnswer); err != nil {
+		t.Errorf("WaitForCall(no-answer) = %v", err)
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	queued = true
+	if _, err := client.Call.WaitForCall(tctx, "req-1", CallCompleted); err != context.DeadlineExceeded {
+		t.Errorf("WaitForCall on a queued call returned %v, want deadline exceeded", err)
+	}
+
+	var body CallMakeResponseBody
+	if err := json.Unmarshal([]byte(`{"api_id": "a", "request_uuid": "r1"}`), &body); err != nil || len(body.RequestUUID) != 1 || body.ApiID != "a" {
+		t.Errorf("Unmarshal = %+v, %v", body, err)
+	}
+	if err := json.Unmarshal([]byte(`{"request_uuid": ["r1", "r2"]}`), &body); err != nil || len(body.RequestUUID) != 2 {
+		t.Errorf("Unmarshal = %+v, %v", body, err)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		path := r.URL.Path[strings.Index(r.URL.Path, "/Call/"):]
+		calls = append(calls, r.Method+" "+path+" "+strings.TrimSpace(string(b)))
+		if r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/Call/") {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": "invalid to"}`)
+			return
+		}
+		fmt.Fprint(w, `{"message": "ok"}`)
+	}))
+	defer server.Close()
+	client := newTestClient(server)
+
+	if _, err := client.Call.Transfer(ctx, "c1", &CallTransferParams{Legs: BothLegs, AlegURL: "http://a/", BlegURL: "http://b/"}); err != nil {
+		t.Errorf("Transfer failed: %v", err)
+	}
+	if _, err := client.Call.Redirect(ctx, "c1", "http://x/", "GET"); err != nil {
+		t.Errorf("Redirect failed: %v", err)
+	}
+	for _, p := range []*CallTransferParams{nil, {AlegURL: ""}, {Legs: BLeg, AlegURL: "http://a/"}, {Legs: "cleg", AlegURL: "http://a/"}} {
+		if _, err := client.Call.Transfer(ctx, "c1", p); err == nil {
+			t.Errorf("Transfer(%+v) did not fail", p)
+		}
+	}
+	if _, _, err := client.Call.WarmTransfer(ctx, "c1", nil); err == nil {
+		t.Errorf("WarmTransfer accepted nil parameters")
+	}
+	_, _, err := client.Call.WarmTransfer(ctx, "c1", &WarmTransferParams{
+		HoldMusicURL: "http://music/",
+		Call:         CallMakeParams{From: "14155550100", To: "14155550101", AnswerURL: "http://join/"},
+	})
+	if !IsValidation(err) {
+		t.Errorf("WarmTransfer returned %v", err)
+	}
+
+	want := []string{
+		`POST /Call/c1/ {"legs":"both","aleg_url":"http://a/","bleg_url":"http://b/"}`,
+		`POST /Call/c1/ {"legs":"aleg","aleg_url":"http://x/","aleg_method":"GET"}`,
+		`POST /Call/c1/Play/ {"urls":"http://music/","legs":"aleg","loop":true}`,
+		`POST /Call/ {"from":"14155550100","to":"14155550101","answer_url":"http://join/"}`,
+		`DELETE /Call/c1/Play/ `,
+	}
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("requests:\n%s\nwant:\n%s", strings.Join(calls, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWarmTransferCancelled(t *testing.T) {
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	unheld := make(chan bool, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/Call/"):
+			// The caller gives up while the new party is dialled.
+			cancel()
+			<-done
+		case r.Method == "DELETE":
+			unheld <- true
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			fmt.Fprint(w, `{"message": "ok"}`)
+		}
+	}))
+	defer server.Close()
+	client := newTestClient(server)
+	client.RetryPolicy = nil
+
+	_, _, err := client.Call.WarmTransfer(cctx, "c1", &WarmTransferParams{
+		HoldMusicURL: "http://music/",
+		Call:         CallMakeParams{From: "14155550100", To: "14155550101", AnswerURL: "http://join/"},
+	})
+	close(done)
+	if len(unheld) == 0 {
+		t.Errorf("WarmTransfer did not stop the hold music after its context was cancelled")
+	}
+	if !errors.Is(err, context.Canceled) || !IsServerError(err) {
+		t.Errorf("WarmTransfer returned %v, want the cancellation joined with the Unhold error", err)
+	}
+}
+
+func TestSIPHeaders(t *testing.T) {
+	h := SIPHeaders{"X-PH-Campaign": "spring sale", "ticket": "42", "Agent-ID": "a/b"}
+	if got, want := h.Encode(), "Agent-ID=a%2Fb,Campaign=spring%20sale,ticket=42"; got != want {
+		t.Errorf("Encode = %q, want %q", got, want)
+	}
+	b, err := json.Marshal(&CallMakeParams{SipHeaders: h})
+	if err != nil || string(b) != `{"sip_headers":"Agent-ID=a%2Fb,Campaign=spring%20sale,ticket=42"}` {
+		t.Errorf("Marshal = %s, %v", b, err)
+	}
+	var p CallMakeParams
+	if err := json.Unmarshal(b, &p); err != nil || len(p.SipHeaders) != 3 || p.SipHeaders["Agent-ID"] != "a/b" {
+		t.Errorf("Unmarshal = %v, %v", p.SipHeaders, err)
+	}
+	long := SIPHeaders{"a": strings.Repeat("x", MaxSIPHeadersLength-2)}
+	if err := long.Validate(); err != nil {
+		t.Errorf("Validate of %d encoded bytes failed: %v", len(long.Encode()), err)
+	}
+	for _, bad := range []SIPHeaders{
+		{"": "x"}, {"X-PH-": "x"}, {"a b": "x"}, {"a=b": "x"},
+		{"X-PH-a": "x", "a": "y"}, {"x-ph-Ticket": "1", "TICKET": "2"},
+		{"a": "line\r\nbreak"}, {"a": "caf\u00e9"}, {"a": "\x00"},
+		{"a": strings.Repeat("x", MaxSIPHeadersLength-1)},
+		{"a": strings.Repeat("/", MaxSIPHeadersLength/3)},
+	} {
+		if bad.Validate() == nil {
+			t.Errorf("Validate(%v) succeeded", bad)
+		}
+	}
+	client := NewClient(nil, "id", "token")
+	if _, _, err := client.Call.Make(ctx, &CallMakeParams{From: "14155550100", To: "14155550101", SipHeaders: SIPHeaders{"a,b": "x"}}); err == nil {
+		t.Errorf("Make accepted an invalid SIP header")
+	}
+
+	form := url.Values{
+		"CallUUID":      {"abc"},
+		"X-PH-Campaign": {"spring%20sale"},
+		"x-ph-ticket":   {"42"},
+		"X-Other":       {"ignored"},
+	}
+	r := httptest.NewRequest("POST", "/answer", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var cb AnswerCallback
+	if err := ParseCallback(r, &cb); err != nil {
+		t.Fatalf("ParseCallback failed: %v", err)
+	}
+	if len(cb.SIPHeaders) != 2 || cb.SIPHeaders["Campaign"] != "spring sale" || cb.SIPHeaders["ticket"] != "42" || cb.CallUUID != "abc" {
+		t.Errorf("SIPHeaders = %v", cb.SIPHeaders)
+	}
+}
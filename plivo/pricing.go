@@ -3,6 +3,8 @@
 
 package plivo
 
+import "context"
+
 type PricingService struct {
 	client *Client
 }
@@ -18,16 +20,16 @@ type Pricing struct {
 type RateMessage map[string][]string
 
 type PricingGetParams struct {
-	CountryISO string
+	CountryISO string `url:"country_iso"`
 }
 
 // Get fetches the pricing for a specified country
-func (s *PricingService) Get(p *PricingGetParams) (*Pricing, *Response, error) {
+func (s *PricingService) Get(ctx context.Context, p *PricingGetParams) (*Pricing, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Pricing/", p)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &Pricing{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return aResp, resp, err
 }
@@ -0,0 +1,33 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"net/url"
+	"reflect"
+
+	"github.com/google/go-querystring/query"
+)
+
+// limitOffset is a utility type for handling offsets in the API calls.
+type limitOffset struct {
+	Limit  int64 `url:"limit,omitempty"`
+	Offset int64 `url:"offset,omitempty"`
+}
+
+// encodeQuery encodes the `url`-tagged fields of params on top of base and
+// returns the resulting query string. A nil params leaves base untouched.
+func encodeQuery(base url.Values, params interface{}) (string, error) {
+	if v := reflect.ValueOf(params); params == nil || v.Kind() == reflect.Ptr && v.IsNil() {
+		return base.Encode(), nil
+	}
+	v, err := query.Values(params)
+	if err != nil {
+		return "", err
+	}
+	for k, vs := range v {
+		base[k] = vs
+	}
+	return base.Encode(), nil
+}
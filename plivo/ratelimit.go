@@ -0,0 +1,161 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by Client.Do when a request made with a
+// WithFailFast context would have to wait for a rate limiter. API errors with
+// status 429 also match it with errors.Is.
+var ErrRateLimited = errors.New("plivo: rate limit exceeded")
+
+// RateLimiter is a token bucket which allows Rate requests per second on
+// average, with bursts of up to Burst requests.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a full token bucket refilled at rate tokens per second.
+// A burst below 1 is treated as 1.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it. If wait is false, no token is taken unless one is available now.
+func (l *RateLimiter) reserve(now time.Time, wait bool) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0, true
+	}
+	if !wait || l.rate <= 0 {
+		return 0, false
+	}
+	d := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+	l.tokens--
+	return d, true
+}
+
+// Allow takes a token if one is available and reports whether it did.
+func (l *RateLimiter) Allow() bool {
+	_, ok := l.reserve(time.Now(), false)
+	return ok
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	d, ok := l.reserve(time.Now(), true)
+	if !ok {
+		return ErrRateLimited
+	}
+	if d == 0 {
+		return nil
+	}
+	if err := sleep(ctx, d); err != nil {
+		l.refund()
+		return err
+	}
+	return nil
+}
+
+// refund gives back a token which was taken but never used.
+func (l *RateLimiter) refund() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+type failFastKey struct{}
+
+// WithFailFast returns a copy of ctx which makes Client.Do return
+// ErrRateLimited instead of blocking when a rate limiter has no tokens left.
+func WithFailFast(ctx context.Context) context.Context {
+	return context.WithValue(ctx, failFastKey{}, true)
+}
+
+// limiters returns the rate limiters which apply to req.
+func (c *Client) limiters(req *http.Request) []*RateLimiter {
+	var ls []*RateLimiter
+	if c.Limiter != nil {
+		ls = append(ls, c.Limiter)
+	}
+	if req.Method == "POST" {
+		switch {
+		case c.CallLimiter != nil && strings.HasSuffix(req.URL.Path, "/Call/"):
+			ls = append(ls, c.CallLimiter)
+		case c.MessageLimiter != nil && strings.HasSuffix(req.URL.Path, "/Message/"):
+			ls = append(ls, c.MessageLimiter)
+		}
+	}
+	return ls
+}
+
+// throttle waits for every rate limiter which applies to req. If one of them
+// refuses, the tokens taken from the others are given back.
+func (c *Client) throttle(ctx context.Context, req *http.Request) error {
+	failFast, _ := ctx.Value(failFastKey{}).(bool)
+	ls := c.limiters(req)
+	for i, l := range ls {
+		var err error
+		if failFast {
+			if !l.Allow() {
+				err = ErrRateLimited
+			}
+		} else {
+			err = l.Wait(ctx)
+		}
+		if err != nil {
+			for _, taken := range ls[:i] {
+				taken.refund()
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// UseAccountRateLimit fetches the account and installs a CallLimiter matching
+// its calls-per-second allowance.
+func (c *Client) UseAccountRateLimit(ctx context.Context) error {
+	acc, _, err := c.Account.Get(ctx)
+	if err != nil {
+		return err
+	}
+	cps, err := strconv.ParseFloat(acc.CpsAllowed, 64)
+	if err != nil || cps <= 0 {
+		return fmt.Errorf("plivo: invalid cps_allowed %q", acc.CpsAllowed)
+	}
+	c.CallLimiter = NewRateLimiter(cps, 1)
+	return nil
+}
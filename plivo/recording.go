@@ -3,30 +3,32 @@
 
 package plivo
 
+import "context"
+
 type RecordingService struct {
 	client *Client
 }
 
 type Recording struct {
-	CallUUID            string `json:"call_uuid,omitempty"`
-	RecordingID         string `json:"recording_id,omitempty"`
-	RecordingType       string `json:"recording_type,omitempty"`
-	RecordingFormat     string `json:"recording_format,omitempty"`
-	ConferenceName      string `json:"conference_name,omitempty"`
-	RecordingURL        string `json:"recording_url,omitempty"`
-	ResourceURI         string `json:"resource_uri,omitempty"`
-	RecordingStartMS    string `json:"recording_start_ms,omitempty"`
-	RecordingEndMS      string `json:"recording_end_ms,omitempty"`
-	RecordingDurationMS string `json:"recording_duration_ms,omitempty"`
+	CallUUID            string     `json:"call_uuid,omitempty"`
+	RecordingID         string     `json:"recording_id,omitempty"`
+	RecordingType       string     `json:"recording_type,omitempty"`
+	RecordingFormat     string     `json:"recording_format,omitempty"`
+	ConferenceName      string     `json:"conference_name,omitempty"`
+	RecordingURL        string     `json:"recording_url,omitempty"`
+	ResourceURI         string     `json:"resource_uri,omitempty"`
+	RecordingStartMS    MillisTime `json:"recording_start_ms,omitzero"`
+	RecordingEndMS      MillisTime `json:"recording_end_ms,omitzero"`
+	RecordingDurationMS string     `json:"recording_duration_ms,omitempty"`
 }
 
 type RecordingGetAllParams struct {
 	// Query parameters.
-	Subaccount string `json:"subaccount,omitempty"`
-	CallUUID   string `json:"call_uuid,omitempty"`
-	AddTime    string `json:"add_time,omitempty"`
-	Limit      int64  `json:"limit:omitempty"`
-	Offset     int64  `json:"offset:omitempty"`
+	Subaccount string     `url:"subaccount,omitempty"`
+	CallUUID   string     `url:"call_uuid,omitempty"`
+	AddTime    TimeFilter `url:"add_time,omitempty"`
+	Limit      int64      `url:"limit,omitempty"`
+	Offset     int64      `url:"offset,omitempty"`
 }
 
 type RecordingGetAllResponseBody struct {
@@ -36,24 +38,42 @@ type RecordingGetAllResponseBody struct {
 }
 
 // GetAll fetches all recordings.
-func (s *RecordingService) GetAll(p *RecordingGetAllParams) ([]*Recording, *Response, error) {
+func (s *RecordingService) GetAll(ctx context.Context, p *RecordingGetAllParams) ([]*Recording, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Recording/", p)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &RecordingGetAllResponseBody{}
-	resp, err := s.client.Do(req, aResp)
-	resp.Meta = aResp.Meta
+	resp, err := s.client.Do(ctx, req, aResp)
+	if resp != nil {
+		resp.Meta = aResp.Meta
+	}
 	return aResp.Objects, resp, err
 }
 
+// GetAllPager returns a Pager which walks all recordings matching p, page by page,
+// starting at p.Offset. A non-zero p.Limit sets the Pager's PageSize.
+func (s *RecordingService) GetAllPager(ctx context.Context, p *RecordingGetAllParams) *Pager[*Recording] {
+	q := RecordingGetAllParams{}
+	if p != nil {
+		q = *p
+	}
+	pg := NewPager(ctx, q.Offset, func(ctx context.Context, limit, offset int64) ([]*Recording, *Meta, error) {
+		q.Limit, q.Offset = limit, offset
+		objs, resp, err := s.GetAll(ctx, &q)
+		return objs, pageMeta(resp), err
+	})
+	pg.PageSize = q.Limit
+	return pg
+}
+
 // Get fetches a specified recording.
-func (s *RecordingService) Get(recordingID string) (*Recording, *Response, error) {
+func (s *RecordingService) Get(ctx context.Context, recordingID string) (*Recording, *Response, error) {
 	req, err := s.client.NewRequest("GET", s.client.authID+"/Recording/"+recordingID+"/", nil)
 	if err != nil {
 		return nil, nil, err
 	}
 	aResp := &Recording{}
-	resp, err := s.client.Do(req, aResp)
+	resp, err := s.client.Do(ctx, req, aResp)
 	return aResp, resp, err
 }
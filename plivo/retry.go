@@ -0,0 +1,179 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how Client.Do retries requests that fail with a
+// transient error: a refused or reset connection, a timeout, a 429 or a 5xx
+// response. Other errors, such as TLS failures or invalid requests, are
+// returned at once.
+//
+// Only idempotent requests (GET and DELETE) are retried by default. Other
+// requests, such as the POST issued by CallService.Make, are retried only
+// when their context has been marked with WithRetry, and then only after an
+// error response or a refused connection, never after a timeout or reset
+// which may have left the request already processed.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// A value below 2 disables retries.
+	MaxAttempts int
+
+	// MinBackoff is the wait before the first retry. Each subsequent wait is
+	// doubled, up to MaxBackoff.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Jitter is the fraction of each wait, between 0 and 1, that is
+	// randomised to spread retries from concurrent callers.
+	Jitter float64
+
+	// RespectRetryAfter makes the client wait for the duration given in a
+	// Retry-After response header instead of the computed backoff, up to
+	// MaxBackoff.
+	RespectRetryAfter bool
+}
+
+// DefaultRetryPolicy is the policy installed by NewClient.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:       3,
+	MinBackoff:        500 * time.Millisecond,
+	MaxBackoff:        10 * time.Second,
+	Jitter:            0.5,
+	RespectRetryAfter: true,
+}
+
+type retryKey struct{}
+
+// WithRetry returns a copy of ctx that allows Client.Do to retry requests
+// which are not idempotent, such as CallService.Make or MessageService.Send.
+// Only use it when a duplicate request is harmless to the caller.
+func WithRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, true)
+}
+
+// idempotent reports whether sending req twice has the effect of sending it once.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "DELETE":
+		return true
+	}
+	return false
+}
+
+// retryAllowed reports whether req may be sent more than once.
+func retryAllowed(ctx context.Context, req *http.Request) bool {
+	if idempotent(req) {
+		return true
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	ok, _ := ctx.Value(retryKey{}).(bool)
+	return ok
+}
+
+// retryable reports whether the outcome of an attempt to send req is a
+// transient failure which may be retried.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			// The request never reached the server.
+			return true
+		}
+		if !idempotent(req) {
+			return false
+		}
+		var ne net.Error
+		return errors.Is(err, syscall.ECONNRESET) || errors.As(err, &ne) && ne.Timeout()
+	}
+	c := resp.StatusCode
+	return c == http.StatusTooManyRequests || 500 <= c && c <= 599 && c != http.StatusNotImplemented
+}
+
+// backoff returns how long to wait after the given (1-based) failed attempt.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if p.RespectRetryAfter && resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if p.MaxBackoff > 0 && d > p.MaxBackoff {
+				d = p.MaxBackoff
+			}
+			return d
+		}
+	}
+	d := float64(p.MinBackoff) * math.Pow(2, float64(attempt-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		j := math.Min(p.Jitter, 1)
+		d = d*(1-j) + d*j*rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(time.Now()); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// rewind returns a copy of req with a fresh body, ready to be sent again.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+// discard drains and closes the body of a response which is being retried,
+// so that its connection can be reused.
+func discard(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	io.CopyN(ioutil.Discard, resp.Body, 4096)
+	resp.Body.Close()
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,127 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf16"
+)
+
+// Encoding is the character encoding an SMS is sent in.
+type Encoding string
+
+const (
+	GSM7 Encoding = "GSM-7"
+	UCS2 Encoding = "UCS-2"
+)
+
+// Capacities of SMS segments: a message which fits in a single segment may
+// use all of it, while each part of a longer one loses room to the
+// concatenation header.
+const (
+	gsm7Single = 160
+	gsm7Multi  = 153
+	ucs2Single = 70
+	ucs2Multi  = 67
+)
+
+// gsm7Basic holds the characters of the GSM 03.38 default alphabet, which
+// take one septet each.
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extended holds the characters of the GSM 03.38 extension table, which
+// take two septets each: an escape and the character.
+const gsm7Extended = "\f^{}\\[~]|€"
+
+// ErrTooManySegments is returned by MessageService.Send for a message which
+// would exceed MessageSendParams.MaxSegments.
+var ErrTooManySegments = errors.New("plivo: message exceeds the segment budget")
+
+// Segmentation describes how a text is split into SMS segments.
+type Segmentation struct {
+	Encoding Encoding
+	// Segments is the number of segments the text is billed as.
+	Segments int
+	// Units is the length of the text: septets for GSM-7 and UTF-16 code
+	// units for UCS-2.
+	Units int
+	// PerSegment is the capacity of each segment in units: 160 or 70 for a
+	// single segment and 153 or 67 for each part of a longer message.
+	PerSegment int
+	// Remaining is the number of units left in the last segment.
+	Remaining int
+}
+
+// Segment returns the encoding and segmentation of text as an SMS. Text using
+// only the GSM 03.38 alphabet and its extension table is sent as GSM-7, any
+// other as UCS-2. Escaped GSM-7 characters and UTF-16 surrogate pairs are
+// never split across segments.
+func Segment(text string) Segmentation {
+	var units []int
+	enc := GSM7
+	for _, c := range text {
+		switch {
+		case strings.ContainsRune(gsm7Basic, c):
+			units = append(units, 1)
+		case strings.ContainsRune(gsm7Extended, c):
+			units = append(units, 2)
+		default:
+			enc = UCS2
+		}
+	}
+	single, multi := gsm7Single, gsm7Multi
+	if enc == UCS2 {
+		units = units[:0]
+		for _, c := range text {
+			units = append(units, len(utf16.Encode([]rune{c})))
+		}
+		single, multi = ucs2Single, ucs2Multi
+	}
+
+	s := Segmentation{Encoding: enc}
+	for _, n := range units {
+		s.Units += n
+	}
+	if s.Units <= single {
+		s.PerSegment = single
+		s.Remaining = single - s.Units
+		if s.Units > 0 {
+			s.Segments = 1
+		}
+		return s
+	}
+
+	s.PerSegment = multi
+	used := 0
+	s.Segments = 1
+	for _, n := range units {
+		if used+n > multi {
+			s.Segments++
+			used = 0
+		}
+		used += n
+	}
+	s.Remaining = multi - used
+	return s
+}
+
+// transliterations map characters outside the GSM 03.38 alphabet to
+// look-alike replacements within it.
+var transliterations = strings.NewReplacer(
+	"‘", "'", "’", "'", "‚", "'", "‛", "'", "′", "'", "´", "'", "`", "'", "‹", "'", "›", "'",
+	"“", `"`, "”", `"`, "„", `"`, "‟", `"`, "″", `"`, "«", `"`, "»", `"`,
+	"‐", "-", "‑", "-", "‒", "-", "–", "-", "—", "-", "―", "-", "−", "-",
+	"…", "...", "•", "*", "ˆ", "^", "˜", "~",
+	"\u00a0", " ", "\u2002", " ", "\u2003", " ", "\u2009", " ", "\u202f", " ",
+	"\u200b", "", "\ufeff", "",
+)
+
+// Transliterate replaces smart punctuation, such as curly quotes, dashes,
+// ellipses and non-breaking spaces, with GSM-7 equivalents, so that text
+// which only differs from the GSM alphabet by them is sent as GSM-7.
+func Transliterate(text string) string {
+	return transliterations.Replace(text)
+}
@@ -0,0 +1,69 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSegment(t *testing.T) {
+	for _, tt := range []struct {
+		text string
+		want Segmentation
+	}{
+		{"", Segmentation{GSM7, 0, 0, 160, 160}},
+		{"Hello", Segmentation{GSM7, 1, 5, 160, 155}},
+		{strings.Repeat("a", 160), Segmentation{GSM7, 1, 160, 160, 0}},
+		{strings.Repeat("a", 161), Segmentation{GSM7, 2, 161, 153, 145}},
+		{"€" + strings.Repeat("a", 158), Segmentation{GSM7, 1, 160, 160, 0}},
+		// The escaped brace would straddle the two segments, so it starts the second.
+		{strings.Repeat("a", 152) + "{" + strings.Repeat("a", 10), Segmentation{GSM7, 2, 164, 153, 141}},
+		{"It’s here", Segmentation{UCS2, 1, 9, 70, 61}},
+		{strings.Repeat("я", 71), Segmentation{UCS2, 2, 71, 67, 63}},
+		// Each emoji is a surrogate pair which must not be split.
+		{strings.Repeat("я", 66) + "😀", Segmentation{UCS2, 1, 68, 70, 2}},
+		{strings.Repeat("я", 66) + "😀😀", Segmentation{UCS2, 1, 70, 70, 0}},
+		{strings.Repeat("я", 66) + "😀😀я", Segmentation{UCS2, 2, 71, 67, 62}},
+	} {
+		if got := Segment(tt.text); got != tt.want {
+			t.Errorf("Segment(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+
+	text := "“Don’t” — wait… ok"
+	if got := Transliterate(text); got != `"Don't" - wait... ok` {
+		t.Errorf("Transliterate = %q", got)
+	}
+	if Segment(Transliterate(text)).Encoding != GSM7 {
+		t.Errorf("transliterated text is not GSM-7")
+	}
+
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p MessageSendParams
+		json.NewDecoder(r.Body).Decode(&p)
+		sent = append(sent, p.Text)
+		fmt.Fprint(w, `{"message_uuid": ["m1"]}`)
+	}))
+	defer server.Close()
+	client := newTestClient(server)
+	long := strings.Repeat("It’s a long message. ", 5)
+	mp := &MessageSendParams{Src: "14155550100", Dst: "14155550101", Text: long, MaxSegments: 1}
+	if _, _, err := client.Message.Send(ctx, mp); !errors.Is(err, ErrTooManySegments) {
+		t.Errorf("Send returned %v, want ErrTooManySegments", err)
+	}
+	mp.Transliterate = true
+	if _, _, err := client.Message.Send(ctx, mp); err != nil {
+		t.Errorf("Send failed: %v", err)
+	}
+	if len(sent) != 1 || sent[0] != strings.Repeat("It's a long message. ", 5) || mp.Text != long {
+		t.Errorf("sent %q", sent)
+	}
+}
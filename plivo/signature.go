@@ -0,0 +1,232 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidSignature is returned when a callback carries no valid Plivo signature.
+	ErrInvalidSignature = errors.New("plivo: invalid callback signature")
+
+	// ErrReplayedRequest is returned when a callback reuses a nonce seen within the replay window.
+	ErrReplayedRequest = errors.New("plivo: replayed callback request")
+)
+
+// defaultReplayWindow is how long nonces are remembered by a SignatureValidator.
+const defaultReplayWindow = 5 * time.Minute
+
+// SignatureValidator checks the X-Plivo-Signature headers Plivo adds to the
+// requests it makes to answer, hangup, message and callback URLs.
+//
+// V3 signatures are preferred, then V2. Both carry a nonce; a nonce seen
+// twice within ReplayWindow is rejected with ErrReplayedRequest. The legacy V1
+// signature has no nonce, so a captured request stripped of its V2 and V3
+// headers could be replayed at will; it is only accepted if AllowV1 is set.
+type SignatureValidator struct {
+	// ReplayWindow is how long nonces are remembered. Defaults to five minutes.
+	ReplayWindow time.Duration
+
+	// AllowV1 accepts requests signed with the legacy V1 signature only,
+	// without replay protection.
+	AllowV1 bool
+
+	// URL returns the URL Plivo called. Set it when the server sits behind a
+	// proxy which rewrites the scheme, host or path. By default it is rebuilt
+	// from the request and its X-Forwarded-Proto header.
+	URL func(r *http.Request) string
+
+	authToken string
+
+	mu    sync.Mutex
+	seen  map[string]time.Time
+	order []seenNonce // seen nonces, oldest first
+}
+
+type seenNonce struct {
+	nonce string
+	at    time.Time
+}
+
+// NewSignatureValidator returns a SignatureValidator for the given auth token.
+func NewSignatureValidator(authToken string) *SignatureValidator {
+	return &SignatureValidator{authToken: authToken, seen: make(map[string]time.Time)}
+}
+
+// SignatureValidator returns a SignatureValidator using the client's auth token.
+func (c *Client) SignatureValidator() *SignatureValidator {
+	return NewSignatureValidator(c.authToken)
+}
+
+// Validate checks the signature of r. It parses the request form, so the
+// POST parameters remain available to the caller through r.PostForm.
+func (v *SignatureValidator) Validate(r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	uri := v.requestURL(r)
+
+	switch {
+	case r.Header.Get("X-Plivo-Signature-V3") != "":
+		nonce := r.Header.Get("X-Plivo-Signature-V3-Nonce")
+		sig := signatureV3(v.authToken, r.Method, uri, nonce, r.PostForm)
+		if !matchAny(r.Header.Get("X-Plivo-Signature-V3"), sig) {
+			return ErrInvalidSignature
+		}
+		return v.checkNonce(nonce)
+	case r.Header.Get("X-Plivo-Signature-V2") != "":
+		nonce := r.Header.Get("X-Plivo-Signature-V2-Nonce")
+		sig := signatureV2(v.authToken, uri, nonce)
+		if !matchAny(r.Header.Get("X-Plivo-Signature-V2"), sig) {
+			return ErrInvalidSignature
+		}
+		return v.checkNonce(nonce)
+	case v.AllowV1 && r.Header.Get("X-Plivo-Signature") != "":
+		if !matchAny(r.Header.Get("X-Plivo-Signature"), signatureV1(v.authToken, uri, r.PostForm)) {
+			return ErrInvalidSignature
+		}
+		return nil
+	}
+	return ErrInvalidSignature
+}
+
+// Middleware returns a handler which responds with 403 Forbidden to requests
+// without a valid signature and passes the others on to next.
+func (v *SignatureValidator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := v.Validate(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// checkNonce records nonce and fails if it was already seen within the replay window.
+func (v *SignatureValidator) checkNonce(nonce string) error {
+	if nonce == "" {
+		return ErrInvalidSignature
+	}
+	window := v.ReplayWindow
+	if window <= 0 {
+		window = defaultReplayWindow
+	}
+	now := time.Now()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.seen == nil {
+		v.seen = make(map[string]time.Time)
+	}
+	// Nonces are recorded in time order, so the expired ones lead the list.
+	i := 0
+	for ; i < len(v.order) && now.Sub(v.order[i].at) > window; i++ {
+		delete(v.seen, v.order[i].nonce)
+	}
+	v.order = v.order[i:]
+	if _, ok := v.seen[nonce]; ok {
+		return ErrReplayedRequest
+	}
+	v.seen[nonce] = now
+	v.order = append(v.order, seenNonce{nonce, now})
+	return nil
+}
+
+// requestURL returns the absolute URL of r as Plivo called it.
+func (v *SignatureValidator) requestURL(r *http.Request) string {
+	if v.URL != nil {
+		return v.URL(r)
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if p := r.Header.Get("X-Forwarded-Proto"); p != "" {
+		scheme = p
+	}
+	return scheme + "://" + r.Host + r.URL.RequestURI()
+}
+
+// signatureV1 is the base64 HMAC-SHA1 of the URL followed by the POST
+// parameters, sorted by key, each written as key then value.
+func signatureV1(authToken, uri string, params url.Values) string {
+	return sign(sha1.New, authToken, uri+concatParams(params, "", ""))
+}
+
+// signatureV2 is the base64 HMAC-SHA256 of the URL, without its query, followed by the nonce.
+func signatureV2(authToken, uri, nonce string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	return sign(sha256.New, authToken, u.Scheme+"://"+u.Host+u.Path+nonce)
+}
+
+// signatureV3 is the base64 HMAC-SHA256 of the URL with its query parameters
+// sorted, then for POST requests a dot and the sorted body parameters, then a
+// dot and the nonce.
+func signatureV3(authToken, method, uri, nonce string, params url.Values) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	payload := u.Scheme + "://" + u.Host + u.Path
+	if q := u.Query(); len(q) > 0 {
+		payload += "?" + concatParams(q, "=", "&")
+	}
+	if method == "POST" {
+		payload += "." + concatParams(params, "", "")
+	}
+	return sign(sha256.New, authToken, payload+"."+nonce)
+}
+
+// concatParams joins params sorted by key, then by value.
+func concatParams(params url.Values, kvSep, sep string) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), params[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, k+kvSep+v)
+		}
+	}
+	return strings.Join(parts, sep)
+}
+
+func sign(h func() hash.Hash, key, payload string) string {
+	mac := hmac.New(h, []byte(key))
+	mac.Write([]byte(payload))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// matchAny reports whether sig is one of the comma-separated signatures in header.
+func matchAny(header, sig string) bool {
+	if sig == "" {
+		return false
+	}
+	for _, s := range strings.Split(header, ",") {
+		if hmac.Equal([]byte(strings.TrimSpace(s)), []byte(sig)) {
+			return true
+		}
+	}
+	return false
+}
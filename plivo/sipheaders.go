@@ -0,0 +1,165 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SIPHeaderPrefix is prepended by Plivo to the name of every custom SIP header.
+const SIPHeaderPrefix = "X-PH-"
+
+// MaxSIPHeadersLength is the most bytes of encoded SIP headers Validate
+// accepts for a single call.
+const MaxSIPHeadersLength = 4096
+
+// SIPHeaders are custom SIP headers sent with a call and forwarded back to
+// its callbacks. Names are given without SIPHeaderPrefix, which is stripped
+// if present, and may contain only letters, digits and dashes. Values may
+// contain only printable ASCII characters; those other than letters and
+// digits are percent-encoded on the wire, and the encoded headers may be at
+// most MaxSIPHeadersLength bytes long.
+//
+// Encode gives the attribute value for the sipHeaders of plivoxml elements.
+type SIPHeaders map[string]string
+
+// trimSIPHeaderPrefix returns name without SIPHeaderPrefix, in any case.
+func trimSIPHeaderPrefix(name string) string {
+	if len(name) >= len(SIPHeaderPrefix) && strings.EqualFold(name[:len(SIPHeaderPrefix)], SIPHeaderPrefix) {
+		return name[len(SIPHeaderPrefix):]
+	}
+	return name
+}
+
+// Validate reports an error for the first invalid header, in sorted order,
+// for names which are the same once SIPHeaderPrefix is stripped, ignoring
+// case, or if the encoded headers are longer than MaxSIPHeadersLength.
+func (h SIPHeaders) Validate() error {
+	seen := make(map[string]string, len(h))
+	for _, name := range h.names() {
+		key := trimSIPHeaderPrefix(name)
+		if key == "" {
+			return fmt.Errorf("plivo: empty SIP header name %q", name)
+		}
+		if prev, ok := seen[strings.ToLower(key)]; ok {
+			return fmt.Errorf("plivo: SIP header names %q and %q are the same", prev, name)
+		}
+		seen[strings.ToLower(key)] = name
+		for _, c := range key {
+			if !isAlnum(c) && c != '-' {
+				return fmt.Errorf("plivo: invalid SIP header name %q", name)
+			}
+		}
+		for _, c := range h[name] {
+			if c < ' ' || c > '~' {
+				return fmt.Errorf("plivo: invalid value for SIP header %q", name)
+			}
+		}
+	}
+	if n := len(h.Encode()); n > MaxSIPHeadersLength {
+		return fmt.Errorf("plivo: SIP headers are %d bytes long, more than %d", n, MaxSIPHeadersLength)
+	}
+	return nil
+}
+
+func (h SIPHeaders) names() []string {
+	names := make([]string, 0, len(h))
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isAlnum(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// Encode returns the headers in the API's "name=value,name=value" format,
+// sorted by name.
+func (h SIPHeaders) Encode() string {
+	var b strings.Builder
+	for i, name := range h.names() {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(trimSIPHeaderPrefix(name))
+		b.WriteByte('=')
+		for _, c := range []byte(h[name]) {
+			if isAlnum(rune(c)) {
+				b.WriteByte(c)
+			} else {
+				fmt.Fprintf(&b, "%%%02X", c)
+			}
+		}
+	}
+	return b.String()
+}
+
+// MarshalJSON encodes the headers as a string in the format of Encode.
+func (h SIPHeaders) MarshalJSON() ([]byte, error) {
+	if err := h.Validate(); err != nil {
+		return nil, err
+	}
+	return []byte(strconv.Quote(h.Encode())), nil
+}
+
+// UnmarshalJSON decodes headers from a string in the format of Encode.
+func (h *SIPHeaders) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*h = nil
+	for _, kv := range strings.Split(s, ",") {
+		if kv == "" {
+			continue
+		}
+		i := strings.IndexByte(kv, '=')
+		if i < 0 {
+			return fmt.Errorf("plivo: invalid SIP header %q", kv)
+		}
+		v, err := url.PathUnescape(kv[i+1:])
+		if err != nil {
+			return err
+		}
+		if *h == nil {
+			*h = SIPHeaders{}
+		}
+		(*h)[trimSIPHeaderPrefix(kv[:i])] = v
+	}
+	return nil
+}
+
+// ParseSIPHeaders returns the X-PH- headers among the parameters of a
+// callback, keyed by name without the prefix and with values decoded.
+func ParseSIPHeaders(params url.Values) SIPHeaders {
+	var h SIPHeaders
+	for name, vs := range params {
+		key := trimSIPHeaderPrefix(name)
+		if key == name || key == "" || len(vs) == 0 {
+			continue
+		}
+		v, err := url.PathUnescape(vs[0])
+		if err != nil {
+			v = vs[0]
+		}
+		if h == nil {
+			h = SIPHeaders{}
+		}
+		h[key] = v
+	}
+	return h
+}
+
+// decodeValues implements valuesDecoder for callbacks.
+func (h *SIPHeaders) decodeValues(params url.Values) error {
+	*h = ParseSIPHeaders(params)
+	return nil
+}
@@ -0,0 +1,156 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivo
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// TimeLayout is the layout of timestamps in API responses.
+const TimeLayout = "2006-01-02 15:04:05-07:00"
+
+// timeLayouts are the layouts accepted when decoding a Time. Dates without a
+// zone, such as Account.Created, are taken to be UTC.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+	time.RFC3339Nano,
+}
+
+// Time is a timestamp in the API's "2006-01-02 15:04:05-07:00" format.
+// Fields of type Time are tagged omitzero, as omitempty has no effect on
+// structs, so that unset timestamps are left out of request bodies.
+type Time struct {
+	time.Time
+}
+
+// ParseTime parses a timestamp in any of the formats used by the API.
+func ParseTime(s string) (Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return Time{t}, nil
+		}
+	}
+	return Time{}, fmt.Errorf("plivo: cannot parse time %q", s)
+}
+
+// String returns the time in TimeLayout, or an empty string if it is zero.
+func (t Time) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(TimeLayout)
+}
+
+// MarshalJSON encodes the time in TimeLayout, or as null if it is zero.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.Quote(t.String())), nil
+}
+
+// UnmarshalJSON decodes a timestamp string. Null and empty strings leave the time zero.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = Time{}
+		return nil
+	}
+	s, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("plivo: cannot parse time %s", data)
+	}
+	return t.UnmarshalText([]byte(s))
+}
+
+// MarshalText encodes the time in TimeLayout, or as empty text if it is
+// zero. It replaces the RFC 3339 encoding of the embedded time.Time, so that
+// text, XML and query encodings agree with MarshalJSON.
+func (t Time) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// EncodeValues implements query.Encoder, leaving zero times out.
+func (t Time) EncodeValues(key string, v *url.Values) error {
+	if !t.IsZero() {
+		v.Set(key, t.String())
+	}
+	return nil
+}
+
+// UnmarshalText decodes a timestamp, as found in callback parameters.
+func (t *Time) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*t = Time{}
+		return nil
+	}
+	parsed, err := ParseTime(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MillisTime is a timestamp given in milliseconds since the Unix epoch, as
+// in Recording.RecordingStartMS.
+type MillisTime struct {
+	time.Time
+}
+
+// Millis returns the time in milliseconds since the Unix epoch.
+func (t MillisTime) Millis() int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// MarshalJSON encodes the time as a string of milliseconds, or as null if it is zero.
+func (t MillisTime) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(strconv.Quote(strconv.FormatInt(t.Millis(), 10))), nil
+}
+
+// UnmarshalJSON decodes milliseconds given either as a number or as a string.
+func (t *MillisTime) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = MillisTime{}
+		return nil
+	}
+	return t.UnmarshalText(bytes.Trim(data, `"`))
+}
+
+// MarshalText encodes the time as milliseconds, or as empty text if it is zero.
+func (t MillisTime) MarshalText() ([]byte, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return []byte(strconv.FormatInt(t.Millis(), 10)), nil
+}
+
+// UnmarshalText decodes milliseconds since the Unix epoch, with an optional fraction.
+func (t *MillisTime) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*t = MillisTime{}
+		return nil
+	}
+	if ms, err := strconv.ParseInt(string(text), 10, 64); err == nil {
+		*t = MillisTime{time.Unix(0, ms*int64(time.Millisecond))}
+		return nil
+	}
+	ms, err := strconv.ParseFloat(string(text), 64)
+	if err != nil {
+		return fmt.Errorf("plivo: cannot parse millisecond time %q", text)
+	}
+	*t = MillisTime{time.Unix(0, int64(ms*float64(time.Millisecond)))}
+	return nil
+}
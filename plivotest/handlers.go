@@ -0,0 +1,671 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivotest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/micrypt/go-plivo/plivo"
+)
+
+func (s *Server) serveAccount(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		acc := s.Account
+		acc.ApiID = "fake-api-id"
+		writeJSON(w, http.StatusOK, acc)
+	case "POST":
+		if decode(w, r, &s.Account) {
+			s.Account.AuthID = AuthID
+			writeMessage(w, http.StatusAccepted, "changed")
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) serveSubaccount(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) == 0 {
+		switch r.Method {
+		case "GET":
+			writeList(w, r, values(s.Subaccounts))
+		case "POST":
+			sacc := &plivo.Subaccount{}
+			if !decode(w, r, sacc) {
+				return
+			}
+			if sacc.Name == "" {
+				writeError(w, http.StatusBadRequest, "name is required")
+				return
+			}
+			s.seq++
+			sacc.AuthID = fmt.Sprintf("SA%018d", s.seq)
+			sacc.AuthToken = s.newID()
+			sacc.Account = s.Account.ResourceURI
+			sacc.ResourceURI = "/v1/Account/" + AuthID + "/Subaccount/" + sacc.AuthID + "/"
+			s.Subaccounts[sacc.AuthID] = sacc
+			writeJSON(w, http.StatusCreated, plivo.CreateResponseBody{
+				ApiID: "fake-api-id", Message: "created", AuthID: sacc.AuthID, AuthToken: sacc.AuthToken,
+			})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	sacc, ok := s.Subaccounts[segs[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, sacc)
+	case "POST":
+		if decode(w, r, sacc) {
+			sacc.AuthID = segs[0]
+			writeMessage(w, http.StatusAccepted, "changed")
+		}
+	case "DELETE":
+		delete(s.Subaccounts, segs[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) serveApplication(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) == 0 {
+		switch r.Method {
+		case "GET":
+			writeList(w, r, values(s.Applications))
+		case "POST":
+			app := &plivo.Application{}
+			if !decode(w, r, app) {
+				return
+			}
+			if app.AppName == "" || app.AnswerURL == "" {
+				writeError(w, http.StatusBadRequest, "app_name and answer_url are required")
+				return
+			}
+			s.seq++
+			app.AppID = fmt.Sprintf("%020d", s.seq)
+			app.ResourceURI = "/v1/Account/" + AuthID + "/Application/" + app.AppID + "/"
+			s.Applications[app.AppID] = app
+			writeJSON(w, http.StatusCreated, plivo.ApplicationCreateResponseBody{
+				ApiID: "fake-api-id", Message: "created", AppID: app.AppID,
+			})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	app, ok := s.Applications[segs[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, app)
+	case "POST":
+		if decode(w, r, app) {
+			app.AppID = segs[0]
+			writeMessage(w, http.StatusAccepted, "changed")
+		}
+	case "DELETE":
+		delete(s.Applications, segs[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) serveCall(w http.ResponseWriter, r *http.Request, segs []string) {
+	live := r.URL.Query().Get("status") == "live"
+
+	if len(segs) == 0 {
+		switch {
+		case r.Method == "GET" && live:
+			writeList(w, r, values(s.LiveCalls))
+		case r.Method == "GET":
+			writeList(w, r, values(s.Calls))
+		case r.Method == "POST":
+			s.makeCall(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	uuid := segs[0]
+	if len(segs) == 1 && r.Method == "GET" {
+		if live {
+			if lc, ok := s.LiveCalls[uuid]; ok {
+				writeJSON(w, http.StatusOK, lc)
+				return
+			}
+		} else if c, ok := s.Calls[uuid]; ok {
+			writeJSON(w, http.StatusOK, c)
+			return
+		}
+		writeError(w, http.StatusNotFound, "call not found")
+		return
+	}
+
+	lc, ok := s.LiveCalls[uuid]
+	if !ok {
+		writeError(w, http.StatusNotFound, "call not found")
+		return
+	}
+	if len(segs) == 1 {
+		switch r.Method {
+		case "POST":
+			writeMessage(w, http.StatusAccepted, "call transferred")
+		case "DELETE":
+			s.endCall(lc, "Normal Hangup")
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	switch r.Method {
+	case "POST":
+		switch segs[1] {
+		case "Record":
+			writeJSON(w, http.StatusAccepted, plivo.CallRecordResponseBody{
+				Message: "call recording started",
+				URL:     "http://s3.amazonaws.com/recordings_2013/" + uuid + ".mp3",
+			})
+		case "Play", "Speak", "DTMF":
+			writeMessage(w, http.StatusAccepted, strings.ToLower(segs[1])+" started")
+		default:
+			writeError(w, http.StatusNotFound, "not found")
+		}
+	case "DELETE":
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// makeCall creates a live call for each destination of a Make request.
+func (s *Server) makeCall(w http.ResponseWriter, r *http.Request) {
+	p := &plivo.CallMakeParams{}
+	if !decode(w, r, p) {
+		return
+	}
+	if p.From == "" || p.To == "" || p.AnswerURL == "" {
+		writeError(w, http.StatusBadRequest, "from, to and answer_url are required")
+		return
+	}
+
+	var uuids []string
+	for _, to := range strings.Split(string(p.To), "<") {
+		uuid := s.newID()
+		s.LiveCalls[uuid] = &plivo.LiveCall{
+			From:         p.From,
+			To:           plivo.PhoneNumber(to),
+			AnswerURL:    p.AnswerURL,
+			CallUUID:     uuid,
+			RequestUUID:  uuid,
+			CallerName:   p.CallerName,
+			CallStatus:   plivo.CallInProgress,
+			SessionStart: plivo.Time{Time: time.Now()},
+		}
+		uuids = append(uuids, uuid)
+	}
+
+	resp := map[string]interface{}{"api_id": "fake-api-id", "message": "call fired"}
+	if len(uuids) == 1 {
+		resp["request_uuid"] = uuids[0]
+	} else {
+		resp["request_uuid"] = uuids
+	}
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// endCall hangs up a live call and records its CDR with the given hangup cause.
+func (s *Server) endCall(lc *plivo.LiveCall, cause string) {
+	delete(s.LiveCalls, lc.CallUUID)
+	now := time.Now()
+	s.Calls[lc.CallUUID] = &plivo.Call{
+		FromNumber:    lc.From,
+		ToNumber:      lc.To,
+		AnswerURL:     lc.AnswerURL,
+		CallUUID:      lc.CallUUID,
+		CallDirection: "outbound",
+		CallDuration:  int64(now.Sub(lc.SessionStart.Time) / time.Second),
+		EndTime:       plivo.Time{Time: now},
+		HangupCause:   cause,
+		ResourceURI:   "/v1/Account/" + AuthID + "/Call/" + lc.CallUUID + "/",
+	}
+}
+
+// HangupCall ends the live call uuid, as if the remote party hung up.
+func (s *Server) HangupCall(uuid string) bool {
+	return s.EndCall(uuid, "Normal Hangup")
+}
+
+// EndCall ends the live call uuid with a hangup cause such as "Busy Line",
+// "No Answer" or "Machine Detected", so that callers see it fail.
+func (s *Server) EndCall(uuid, hangupCause string) bool {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+	lc, ok := s.LiveCalls[uuid]
+	if ok {
+		s.endCall(lc, hangupCause)
+	}
+	return ok
+}
+
+func (s *Server) serveRequest(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) != 1 || r.Method != "DELETE" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if _, ok := s.LiveCalls[segs[0]]; !ok {
+		writeError(w, http.StatusNotFound, "request not found")
+		return
+	}
+	delete(s.LiveCalls, segs[0])
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) serveMessage(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) == 0 {
+		switch r.Method {
+		case "GET":
+			writeList(w, r, values(s.Messages))
+		case "POST":
+			s.sendMessage(w, r)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	m, ok := s.Messages[segs[0]]
+	if !ok || r.Method != "GET" {
+		writeError(w, http.StatusNotFound, "message not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+// sendMessage queues a message for each destination of a Send request.
+func (s *Server) sendMessage(w http.ResponseWriter, r *http.Request) {
+	p := &plivo.MessageSendParams{}
+	if !decode(w, r, p) {
+		return
+	}
+	if p.Src == "" || p.Dst == "" || p.Text == "" {
+		writeError(w, http.StatusBadRequest, "src, dst and text are required")
+		return
+	}
+
+	resp := &plivo.MessageSendResponseBody{ApiID: "fake-api-id", Message: "message(s) queued"}
+	for _, dst := range strings.Split(string(p.Dst), "<") {
+		uuid := s.newID()
+		s.Messages[uuid] = &plivo.Message{
+			FromNumber:       p.Src,
+			ToNumber:         plivo.PhoneNumber(dst),
+			MessageType:      "sms",
+			MessageDirection: "outbound",
+			MessageState:     "queued",
+			MessageUUID:      uuid,
+			MessageTime:      plivo.Time{Time: time.Now()},
+			ResourceURI:      "/v1/Account/" + AuthID + "/Message/" + uuid + "/",
+		}
+		resp.MessageUUID = append(resp.MessageUUID, uuid)
+	}
+	writeJSON(w, http.StatusAccepted, resp)
+}
+
+func (s *Server) serveNumber(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) == 0 {
+		switch r.Method {
+		case "GET":
+			writeList(w, r, values(s.Numbers))
+		case "POST":
+			p := &plivo.NumberAddParams{}
+			if !decode(w, r, p) {
+				return
+			}
+			for _, n := range strings.Split(p.Numbers, ",") {
+				s.Numbers[n] = &plivo.Number{
+					Number:      plivo.PhoneNumber(n),
+					NumberType:  p.NumberType,
+					Application: p.AppID,
+					AddedOn:     plivo.Time{Time: time.Now()},
+					ResourceURI: "/v1/Account/" + AuthID + "/Number/" + n + "/",
+				}
+			}
+			writeMessage(w, http.StatusAccepted, "changed")
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	n, ok := s.Numbers[segs[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "number not found")
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, n)
+	case "POST":
+		p := &plivo.NumberEditParams{}
+		if decode(w, r, p) {
+			n.Application = p.AppID
+			writeMessage(w, http.StatusAccepted, "changed")
+		}
+	case "DELETE":
+		delete(s.Numbers, segs[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// serveAvailableNumber serves number groups from Available, keyed by group ID.
+func (s *Server) serveAvailableNumber(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) == 0 && r.Method == "GET" {
+		writeList(w, r, values(s.Available))
+		return
+	}
+	if len(segs) != 1 || r.Method != "POST" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	n, ok := s.Available[segs[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "number group not found")
+		return
+	}
+	delete(s.Available, segs[0])
+	rented := *n
+	rented.PlivoNumber = true
+	s.Numbers[string(n.Number)] = &rented
+	writeJSON(w, http.StatusCreated, plivo.NumberRentalResponseBody{
+		Numbers: []*plivo.NumberRental{{Number: n.Number}},
+		Status:  "fulfilled",
+	})
+}
+
+func (s *Server) serveEndpoint(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) == 0 {
+		switch r.Method {
+		case "GET":
+			writeList(w, r, values(s.Endpoints))
+		case "POST":
+			ep := &plivo.Endpoint{}
+			if !decode(w, r, ep) {
+				return
+			}
+			if ep.Username == "" || ep.Password == "" || ep.Alias == "" {
+				writeError(w, http.StatusBadRequest, "username, password and alias are required")
+				return
+			}
+			s.seq++
+			ep.EndpointID = fmt.Sprintf("%014d", s.seq)
+			ep.Username = fmt.Sprintf("%s%d", ep.Username, s.seq)
+			ep.SIPURI = "sip:" + ep.Username + "@phone.plivo.com"
+			ep.ResourceURI = "/v1/Account/" + AuthID + "/Endpoint/" + ep.EndpointID + "/"
+			s.Endpoints[ep.EndpointID] = ep
+			writeJSON(w, http.StatusCreated, map[string]string{
+				"api_id": "fake-api-id", "message": "created",
+				"endpoint_id": ep.EndpointID, "username": ep.Username, "alias": ep.Alias,
+			})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	ep, ok := s.Endpoints[segs[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "endpoint not found")
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, ep)
+	case "POST":
+		if decode(w, r, ep) {
+			ep.EndpointID = segs[0]
+			writeMessage(w, http.StatusAccepted, "changed")
+		}
+	case "DELETE":
+		delete(s.Endpoints, segs[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) serveConference(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) == 0 {
+		switch r.Method {
+		case "GET":
+			names := []string{}
+			for _, c := range values(s.Conferences) {
+				names = append(names, c.ConferenceName)
+			}
+			writeJSON(w, http.StatusOK, plivo.ConferenceGetAllAllResponseBody{ApiID: "fake-api-id", Conferences: names})
+		case "DELETE":
+			s.Conferences = make(map[string]*plivo.Conference)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	c, ok := s.Conferences[segs[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "conference not found")
+		return
+	}
+	switch {
+	case len(segs) == 1 && r.Method == "GET":
+		writeJSON(w, http.StatusOK, c)
+	case len(segs) == 1 && r.Method == "DELETE":
+		delete(s.Conferences, segs[0])
+		w.WriteHeader(http.StatusNoContent)
+	case len(segs) == 2 && segs[1] == "Record" && r.Method == "POST":
+		writeJSON(w, http.StatusAccepted, plivo.ConferenceRecordResponseBody{
+			Message: "conference recording started",
+			Url:     "http://s3.amazonaws.com/recordings_2013/" + segs[0] + ".mp3",
+		})
+	case len(segs) == 2 && segs[1] == "Record" && r.Method == "DELETE":
+		w.WriteHeader(http.StatusNoContent)
+	case len(segs) == 3 && segs[1] == "Member" && r.Method == "DELETE":
+		c.Members = removeMembers(c.Members, segs[2])
+		w.WriteHeader(http.StatusNoContent)
+	case len(segs) == 4 && segs[1] == "Member" && r.Method == "POST":
+		writeMessage(w, http.StatusAccepted, strings.ToLower(segs[3])+" done")
+	case len(segs) == 4 && segs[1] == "Member" && r.Method == "DELETE":
+		if segs[3] == "Kick" {
+			c.Members = removeMembers(c.Members, segs[2])
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// removeMembers removes the comma-separated member IDs, or all members, from ms.
+func removeMembers(ms []plivo.Member, ids string) []plivo.Member {
+	if ids == "all" {
+		return nil
+	}
+	var kept []plivo.Member
+	for _, m := range ms {
+		drop := false
+		for _, id := range strings.Split(ids, ",") {
+			if m.MemberID == id {
+				drop = true
+			}
+		}
+		if !drop {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+func (s *Server) serveRecording(w http.ResponseWriter, r *http.Request, segs []string) {
+	if r.Method != "GET" {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if len(segs) == 0 {
+		writeList(w, r, values(s.Recordings))
+		return
+	}
+	rec, ok := s.Recordings[segs[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "recording not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, rec)
+}
+
+// servePricing serves Pricing, keyed by country ISO code.
+func (s *Server) servePricing(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) != 0 || r.Method != "GET" {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	p, ok := s.Pricing[r.URL.Query().Get("country_iso")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "pricing not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) serveIncomingCarrier(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) == 0 {
+		switch r.Method {
+		case "GET":
+			writeList(w, r, values(s.IncomingCarriers))
+		case "POST":
+			p := &plivo.IncomingCarrierAddParams{}
+			if !decode(w, r, p) {
+				return
+			}
+			if p.Name == "" || p.IPSet == "" {
+				writeError(w, http.StatusBadRequest, "name and ip_set are required")
+				return
+			}
+			s.seq++
+			id := fmt.Sprintf("%014d", s.seq)
+			s.IncomingCarriers[id] = &plivo.IncomingCarrier{
+				CarrierID:   id,
+				Name:        p.Name,
+				IPSet:       p.IPSet,
+				Voice:       true,
+				ResourceURI: "/v1/Account/" + AuthID + "/IncomingCarrier/" + id + "/",
+			}
+			writeMessage(w, http.StatusCreated, "created")
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	c, ok := s.IncomingCarriers[segs[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "carrier not found")
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, c)
+	case "POST":
+		p := &plivo.IncomingCarrierModifyParams{}
+		if !decode(w, r, p) {
+			return
+		}
+		if p.Name != "" {
+			c.Name = p.Name
+		}
+		if p.IPSet != "" {
+			c.IPSet = p.IPSet
+		}
+		writeMessage(w, http.StatusAccepted, "changed")
+	case "DELETE":
+		delete(s.IncomingCarriers, segs[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) serveOutgoingCarrier(w http.ResponseWriter, r *http.Request, segs []string) {
+	if len(segs) == 0 {
+		switch r.Method {
+		case "GET":
+			writeList(w, r, values(s.OutgoingCarriers))
+		case "POST":
+			p := &plivo.OutgoingCarrierAddParams{}
+			if !decode(w, r, p) {
+				return
+			}
+			if p.Name == "" || p.Address == "" {
+				writeError(w, http.StatusBadRequest, "name and address are required")
+				return
+			}
+			s.seq++
+			id := fmt.Sprintf("%014d", s.seq)
+			s.OutgoingCarriers[id] = &plivo.OutgoingCarrier{
+				CarrierID:   id,
+				Name:        p.Name,
+				IPSet:       p.Address,
+				ResourceURI: "/v1/Account/" + AuthID + "/OutgoingCarrier/" + id + "/",
+			}
+			writeMessage(w, http.StatusCreated, "created")
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+		return
+	}
+
+	c, ok := s.OutgoingCarriers[segs[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "carrier not found")
+		return
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, http.StatusOK, c)
+	case "POST":
+		p := &plivo.OutgoingCarrierModifyParams{}
+		if !decode(w, r, p) {
+			return
+		}
+		if p.Name != "" {
+			c.Name = p.Name
+		}
+		if p.IPSet != "" {
+			c.IPSet = p.IPSet
+		}
+		writeMessage(w, http.StatusAccepted, "changed")
+	case "DELETE":
+		delete(s.OutgoingCarriers, segs[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
@@ -0,0 +1,309 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/micrypt/go-plivo/plivo"
+)
+
+var ctx = context.Background()
+
+func TestAccount(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	acc, _, err := client.Account.Get(ctx)
+	if err != nil || acc.AuthID != AuthID {
+		t.Fatalf("AccountGet = %+v, %v", acc, err)
+	}
+	if _, _, err := client.Account.Modify(ctx, &plivo.Account{Name: "renamed"}); err != nil {
+		t.Fatalf("AccountModify failed: %v", err)
+	}
+	if got, _, err := client.Account.Get(ctx); err != nil || got.Name != "renamed" || got.CashCredits != acc.CashCredits {
+		t.Errorf("AccountGet after rename = %+v, %v; want the balance kept", got, err)
+	}
+
+	sacc := &plivo.Subaccount{Name: "sub"}
+	if _, err := client.Account.CreateSubaccount(ctx, sacc); err != nil || sacc.AuthID == "" {
+		t.Fatalf("CreateSubaccount = %+v, %v", sacc, err)
+	}
+	got, _, err := client.Account.GetSubaccount(ctx, sacc.AuthID)
+	if err != nil || got.Name != "sub" {
+		t.Errorf("GetSubaccount = %+v, %v", got, err)
+	}
+	if _, err := client.Account.DeleteSubaccount(ctx, sacc.AuthID); err != nil {
+		t.Errorf("DeleteSubaccount failed: %v", err)
+	}
+	if _, _, err := client.Account.GetSubaccount(ctx, sacc.AuthID); err == nil {
+		t.Errorf("GetSubaccount found a deleted subaccount")
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := plivo.NewClient(nil, AuthID, "wrong")
+	client.BaseURL = srv.Client().BaseURL
+
+	if _, resp, err := client.Account.Get(ctx); err == nil || resp.StatusCode != 401 {
+		t.Errorf("AccountGet with a bad token returned %v", err)
+	}
+}
+
+func TestApplication(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	app, _, err := client.Application.Create(ctx, &plivo.Application{AppName: "app", AnswerURL: "http://example.com/"})
+	if err != nil || app.AppID == "" {
+		t.Fatalf("ApplicationCreate = %+v, %v", app, err)
+	}
+	apps, _, err := client.Application.GetApplications(ctx, 0, 0)
+	if err != nil || len(apps) != 1 || apps[0].AppID != app.AppID {
+		t.Errorf("GetApplications = %v, %v", apps, err)
+	}
+	if _, err := client.Application.Delete(ctx, app.AppID); err != nil {
+		t.Errorf("ApplicationDelete failed: %v", err)
+	}
+}
+
+func TestCall(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	cp := &plivo.CallMakeParams{From: "14155550100", To: "14155550101", AnswerURL: "http://example.com/"}
+	body, _, err := client.Call.Make(ctx, cp)
+	if err != nil || len(body.RequestUUID) != 1 {
+		t.Fatalf("CallMake = %+v, %v", body, err)
+	}
+	live, _, err := client.Call.GetAllLive(ctx)
+	if err != nil || len(live) != 1 || live[0].CallUUID != body.RequestUUID[0] {
+		t.Fatalf("GetAllLive = %v, %v", live, err)
+	}
+	uuid := live[0].CallUUID
+	if c, err := client.Call.WaitForCall(ctx, uuid, plivo.CallInProgress); err != nil || c.CallUUID != uuid {
+		t.Errorf("WaitForCall(in-progress) = %+v, %v", c, err)
+	}
+
+	if _, err := client.Call.Speak(ctx, uuid, &plivo.CallSpeakParams{Text: "Hi"}); err != nil {
+		t.Errorf("CallSpeak failed: %v", err)
+	}
+	if _, err := client.Call.Redirect(ctx, uuid, "http://example.com/next", ""); err != nil {
+		t.Errorf("CallRedirect failed: %v", err)
+	}
+	if _, err := client.Call.Hangup(ctx, uuid); err != nil {
+		t.Errorf("CallHangup failed: %v", err)
+	}
+	call, _, err := client.Call.Get(ctx, uuid)
+	if err != nil || call.ToNumber != "14155550101" {
+		t.Errorf("CallGet = %+v, %v", call, err)
+	}
+	if _, _, err := client.Call.GetLive(ctx, uuid); err == nil {
+		t.Errorf("GetLive found a call which was hung up")
+	}
+	if c, err := client.Call.WaitForCall(ctx, uuid); err != nil || c.State() != plivo.CallCompleted {
+		t.Errorf("WaitForCall = %+v, %v", c, err)
+	}
+
+	body, _, err = client.Call.Make(ctx, &plivo.CallMakeParams{From: "14155550100", To: "14155550102<14155550103", AnswerURL: "http://example.com/"})
+	if err != nil || len(body.RequestUUID) != 2 {
+		t.Fatalf("CallMake = %+v, %v", body, err)
+	}
+	srv.EndCall(body.RequestUUID[0], "Busy Line")
+	srv.EndCall(body.RequestUUID[1], "Machine Detected")
+	if _, err := client.Call.WaitForCall(ctx, body.RequestUUID[0]); !errors.Is(err, plivo.ErrCallBusy) {
+		t.Errorf("WaitForCall returned %v, want ErrCallBusy", err)
+	}
+	c, err := client.Call.WaitForCall(ctx, body.RequestUUID[1])
+	var cerr *plivo.CallError
+	if !errors.As(err, &cerr) || cerr.State != plivo.CallMachine || c.CallUUID != body.RequestUUID[1] {
+		t.Errorf("WaitForCall = %+v, %v; want machine CallError", c, err)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	body, _, err := client.Message.Send(ctx, &plivo.MessageSendParams{Src: "14155550100", Dst: "14155550101<14155550102", Text: "Hi"})
+	if err != nil || len(body.MessageUUID) != 2 {
+		t.Fatalf("MessageSend = %+v, %v", body, err)
+	}
+	m, _, err := client.Message.Get(ctx, body.MessageUUID[1])
+	if err != nil || m.ToNumber != "14155550102" {
+		t.Errorf("MessageGet = %+v, %v", m, err)
+	}
+	if _, _, err := client.Message.Send(ctx, &plivo.MessageSendParams{Src: "14155550100"}); err == nil {
+		t.Errorf("MessageSend accepted a message without dst")
+	}
+}
+
+func TestNumber(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	srv.Available["g1"] = &plivo.Number{GroupID: "g1", Number: "14155550199"}
+	rented, _, err := client.Number.Rent(ctx, "g1", &plivo.NumberRentalParams{})
+	if err != nil || len(rented) != 1 {
+		t.Fatalf("NumberRent = %v, %v", rented, err)
+	}
+	if _, err := client.Number.Edit(ctx, "14155550199", &plivo.NumberEditParams{AppID: "app"}); err != nil {
+		t.Errorf("NumberEdit failed: %v", err)
+	}
+	n, _, err := client.Number.Get(ctx, "14155550199")
+	if err != nil || n.Application != "app" {
+		t.Errorf("NumberGet = %+v, %v", n, err)
+	}
+	if _, err := client.Number.Unrent(ctx, "14155550199"); err != nil {
+		t.Errorf("NumberUnrent failed: %v", err)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	if _, _, err := client.Endpoint.Create(ctx, &plivo.Endpoint{Username: "alice", Password: "secret", Alias: "Alice"}); err != nil {
+		t.Fatalf("EndpointCreate failed: %v", err)
+	}
+	eps, _, err := client.Endpoint.GetEndpoints(ctx, 0, 0)
+	if err != nil || len(eps) != 1 {
+		t.Fatalf("GetEndpoints = %v, %v", eps, err)
+	}
+	if _, err := client.Endpoint.Delete(ctx, eps[0].EndpointID); err != nil {
+		t.Errorf("EndpointDelete failed: %v", err)
+	}
+}
+
+func TestConferenceAndRecording(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	srv.Conferences["room"] = &plivo.Conference{ConferenceName: "room", Members: []plivo.Member{{MemberID: "1"}, {MemberID: "2"}}}
+	srv.Recordings["r1"] = &plivo.Recording{RecordingID: "r1", ConferenceName: "room"}
+
+	names, _, err := client.Conference.GetAll(ctx)
+	if err != nil || len(names) != 1 || names[0] != "room" {
+		t.Fatalf("ConferenceGetAll = %v, %v", names, err)
+	}
+	if _, err := client.Conference.KickMembers(ctx, "room", "1"); err != nil {
+		t.Errorf("KickMembers failed: %v", err)
+	}
+	conf, _, err := client.Conference.Get(ctx, "room")
+	if err != nil || len(conf.Members) != 1 {
+		t.Errorf("ConferenceGet = %+v, %v", conf, err)
+	}
+	if _, err := client.Conference.HangupAll(ctx); err != nil {
+		t.Errorf("HangupAll failed: %v", err)
+	}
+
+	rec, _, err := client.Recording.Get(ctx, "r1")
+	if err != nil || rec.ConferenceName != "room" {
+		t.Errorf("RecordingGet = %+v, %v", rec, err)
+	}
+}
+
+func TestPricing(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	srv.Pricing["GB"] = &plivo.Pricing{Country: "United Kingdom", CountryISO: "GB", CountryCode: "44"}
+	p, _, err := client.Pricing.Get(ctx, &plivo.PricingGetParams{CountryISO: "GB"})
+	if err != nil || p.CountryCode != "44" {
+		t.Errorf("PricingGet = %+v, %v", p, err)
+	}
+	if _, _, err := client.Pricing.Get(ctx, &plivo.PricingGetParams{CountryISO: "ZZ"}); !plivo.IsNotFound(err) {
+		t.Errorf("PricingGet for an unknown country returned %v", err)
+	}
+}
+
+func TestCarriers(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	if _, err := client.IncomingCarrier.Add(ctx, &plivo.IncomingCarrierAddParams{Name: "in", IPSet: "10.0.0.1"}); err != nil {
+		t.Fatalf("IncomingCarrierAdd failed: %v", err)
+	}
+	in, _, err := client.IncomingCarrier.GetAll(ctx, nil)
+	if err != nil || len(in) != 1 || in[0].Name != "in" {
+		t.Fatalf("IncomingCarrierGetAll = %v, %v", in, err)
+	}
+	if _, err := client.IncomingCarrier.Modify(ctx, in[0].CarrierID, &plivo.IncomingCarrierModifyParams{Name: "renamed"}); err != nil {
+		t.Errorf("IncomingCarrierModify failed: %v", err)
+	}
+	if c, _, err := client.IncomingCarrier.Get(ctx, in[0].CarrierID); err != nil || c.Name != "renamed" {
+		t.Errorf("IncomingCarrierGet = %+v, %v", c, err)
+	}
+	if _, err := client.IncomingCarrier.Remove(ctx, in[0].CarrierID); err != nil {
+		t.Errorf("IncomingCarrierRemove failed: %v", err)
+	}
+
+	if _, err := client.OutgoingCarrier.Add(ctx, &plivo.OutgoingCarrierAddParams{Name: "out", Address: "sip.example.com"}); err != nil {
+		t.Fatalf("OutgoingCarrierAdd failed: %v", err)
+	}
+	out, _, err := client.OutgoingCarrier.GetAll(ctx, nil)
+	if err != nil || len(out) != 1 {
+		t.Fatalf("OutgoingCarrierGetAll = %v, %v", out, err)
+	}
+	if _, err := client.OutgoingCarrier.Remove(ctx, out[0].CarrierID); err != nil {
+		t.Errorf("OutgoingCarrierRemove failed: %v", err)
+	}
+	if _, _, err := client.OutgoingCarrier.Get(ctx, out[0].CarrierID); !plivo.IsNotFound(err) {
+		t.Errorf("OutgoingCarrierGet after Remove returned %v", err)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+	client := srv.Client()
+
+	for i := 0; i < 45; i++ {
+		srv.Subaccounts[fmt.Sprintf("SA%03d", i)] = &plivo.Subaccount{AuthID: fmt.Sprintf("SA%03d", i)}
+	}
+	saccs, resp, err := client.Account.GetSubaccounts(ctx, 10, 40)
+	if err != nil || len(saccs) != 5 || saccs[0].AuthID != "SA040" || resp.Meta.TotalCount != 45 {
+		t.Errorf("GetSubaccounts(10, 40) = %d subaccounts, %+v, %v", len(saccs), resp.Meta, err)
+	}
+
+	p := client.Account.GetSubaccountsPager(ctx)
+	p.PageSize = 10
+	n := 0
+	for p.Next() {
+		if want := fmt.Sprintf("SA%03d", n); p.Value().AuthID != want {
+			t.Fatalf("GetSubaccountsPager returned %s at %d, want %s", p.Value().AuthID, n, want)
+		}
+		n++
+	}
+	if p.Err() != nil || n != 45 {
+		t.Errorf("GetSubaccountsPager walked %d subaccounts, err %v", n, p.Err())
+	}
+
+	for i := 0; i < 25; i++ {
+		uuid := fmt.Sprintf("call-%03d", i)
+		srv.Calls[uuid] = &plivo.Call{CallUUID: uuid}
+	}
+	cp := client.Call.GetAllPager(ctx, &plivo.CallGetAllParams{Limit: 4, Offset: 10})
+	var uuids []string
+	for cp.Next() {
+		uuids = append(uuids, cp.Value().CallUUID)
+	}
+	if cp.Err() != nil || cp.PageSize != 4 || len(uuids) != 15 || uuids[0] != "call-010" || uuids[14] != "call-024" {
+		t.Errorf("GetAllPager(limit 4, offset 10) walked %v, err %v", uuids, cp.Err())
+	}
+}
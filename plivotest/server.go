@@ -0,0 +1,256 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+/*
+Package plivotest provides an in-process fake of the Plivo API for tests.
+
+The fake keeps accounts, calls, messages, numbers, applications, endpoints,
+conferences, recordings, pricing and carriers in memory, so code built on the
+plivo package can be tested offline:
+
+  func TestReminder(t *testing.T) {
+    srv := plivotest.NewServer()
+    defer srv.Close()
+
+    client := srv.Client()
+    _, _, err := client.Call.Make(ctx, &plivo.CallMakeParams{...})
+    ...
+  }
+*/
+package plivotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/micrypt/go-plivo/plivo"
+)
+
+const (
+	// AuthID and AuthToken are the credentials accepted by a Server.
+	AuthID    = "MAFAKEFAKEFAKEFAKEFA"
+	AuthToken = "fake-auth-token"
+)
+
+// Server is a fake Plivo API. Its exported maps hold the in-memory state and
+// may be read or seeded by tests while holding Mu.
+type Server struct {
+	*httptest.Server
+
+	Mu sync.Mutex
+
+	Account      plivo.Account
+	Subaccounts  map[string]*plivo.Subaccount
+	Applications map[string]*plivo.Application
+	Calls        map[string]*plivo.Call
+	LiveCalls    map[string]*plivo.LiveCall
+	Messages     map[string]*plivo.Message
+	Numbers      map[string]*plivo.Number
+	Available    map[string]*plivo.Number
+	Endpoints    map[string]*plivo.Endpoint
+	Conferences  map[string]*plivo.Conference
+	Recordings   map[string]*plivo.Recording
+
+	Pricing          map[string]*plivo.Pricing
+	IncomingCarriers map[string]*plivo.IncomingCarrier
+	OutgoingCarriers map[string]*plivo.OutgoingCarrier
+
+	// Requests records the method and path of every request received.
+	Requests []string
+
+	seq int
+}
+
+// NewServer starts and returns a new fake Plivo API server.
+// The caller should call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		Account: plivo.Account{
+			AuthID:      AuthID,
+			Name:        "Fake Account",
+			CpsAllowed:  "2",
+			CashCredits: plivo.MustParseMoney("100.00000"),
+			Enabled:     true,
+			ResourceURI: "/v1/Account/" + AuthID + "/",
+		},
+		Subaccounts:  make(map[string]*plivo.Subaccount),
+		Applications: make(map[string]*plivo.Application),
+		Calls:        make(map[string]*plivo.Call),
+		LiveCalls:    make(map[string]*plivo.LiveCall),
+		Messages:     make(map[string]*plivo.Message),
+		Numbers:      make(map[string]*plivo.Number),
+		Available:    make(map[string]*plivo.Number),
+		Endpoints:    make(map[string]*plivo.Endpoint),
+		Conferences:  make(map[string]*plivo.Conference),
+		Recordings:   make(map[string]*plivo.Recording),
+
+		Pricing:          make(map[string]*plivo.Pricing),
+		IncomingCarriers: make(map[string]*plivo.IncomingCarrier),
+		OutgoingCarriers: make(map[string]*plivo.OutgoingCarrier),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a plivo.Client which talks to the server, with retries disabled.
+func (s *Server) Client() *plivo.Client {
+	c := plivo.NewClient(s.Server.Client(), AuthID, AuthToken)
+	c.BaseURL, _ = url.Parse(s.URL + "/v1/Account/")
+	c.RetryPolicy = nil
+	return c
+}
+
+// newID returns a new unique identifier shaped like a Plivo UUID.
+func (s *Server) newID() string {
+	s.seq++
+	return fmt.Sprintf("%08x-0000-4000-8000-%012x", s.seq, s.seq)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+
+	s.Requests = append(s.Requests, r.Method+" "+r.URL.Path)
+
+	prefix := "/v1/Account/" + AuthID + "/"
+	if id, token, ok := r.BasicAuth(); !ok || id != AuthID || token != AuthToken {
+		writeError(w, http.StatusUnauthorized, "authentication failed")
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, prefix) && r.URL.Path != strings.TrimSuffix(prefix, "/") {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	var segs []string
+	for _, seg := range strings.Split(strings.TrimPrefix(r.URL.Path, prefix), "/") {
+		if seg != "" {
+			segs = append(segs, seg)
+		}
+	}
+	if len(segs) == 0 {
+		s.serveAccount(w, r)
+		return
+	}
+
+	handlers := map[string]func(http.ResponseWriter, *http.Request, []string){
+		"Subaccount":           s.serveSubaccount,
+		"Application":          s.serveApplication,
+		"Call":                 s.serveCall,
+		"Request":              s.serveRequest,
+		"Message":              s.serveMessage,
+		"Number":               s.serveNumber,
+		"AvailableNumberGroup": s.serveAvailableNumber,
+		"Endpoint":             s.serveEndpoint,
+		"Conference":           s.serveConference,
+		"Recording":            s.serveRecording,
+		"Pricing":              s.servePricing,
+		"IncomingCarrier":      s.serveIncomingCarrier,
+		"OutgoingCarrier":      s.serveOutgoingCarrier,
+	}
+	h, ok := handlers[segs[0]]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	h(w, r, segs[1:])
+}
+
+// apiResponse is the envelope of simple API responses.
+type apiResponse struct {
+	ApiID   string `json:"api_id"`
+	Message string `json:"message,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// listResponse is the envelope of paginated API responses.
+type listResponse struct {
+	ApiID   string      `json:"api_id"`
+	Meta    listMeta    `json:"meta"`
+	Objects interface{} `json:"objects"`
+}
+
+type listMeta struct {
+	Limit      int64   `json:"limit"`
+	Offset     int64   `json:"offset"`
+	TotalCount int64   `json:"total_count"`
+	Next       *string `json:"next"`
+	Previous   *string `json:"previous"`
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, apiResponse{ApiID: "fake-api-id", Error: msg})
+}
+
+func writeMessage(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, apiResponse{ApiID: "fake-api-id", Message: msg})
+}
+
+// decode reads a JSON request body into v.
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// writeList writes the page of objs selected by the limit and offset query parameters.
+func writeList[T any](w http.ResponseWriter, r *http.Request, objs []T) {
+	n := int64(len(objs))
+	q := r.URL.Query()
+	limit, _ := strconv.ParseInt(q.Get("limit"), 10, 64)
+	offset, _ := strconv.ParseInt(q.Get("offset"), 10, 64)
+	if limit <= 0 || limit > 20 {
+		limit = 20
+	}
+	if offset < 0 || offset > n {
+		offset = n
+	}
+	hi := offset + limit
+	if hi > n {
+		hi = n
+	}
+
+	meta := listMeta{Limit: limit, Offset: offset, TotalCount: n}
+	if hi < n {
+		next := fmt.Sprintf("%s?limit=%d&offset=%d", r.URL.Path, limit, hi)
+		meta.Next = &next
+	}
+	if offset > 0 {
+		prev := offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		previous := fmt.Sprintf("%s?limit=%d&offset=%d", r.URL.Path, limit, prev)
+		meta.Previous = &previous
+	}
+	writeJSON(w, http.StatusOK, listResponse{ApiID: "fake-api-id", Meta: meta, Objects: objs[offset:hi]})
+}
+
+// values returns the values of m ordered by key.
+func values[T any](m map[string]T) []T {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	vs := make([]T, len(keys))
+	for i, k := range keys {
+		vs[i] = m[k]
+	}
+	return vs
+}
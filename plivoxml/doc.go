@@ -0,0 +1,24 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+/*
+Package plivoxml builds the Plivo XML documents returned by answer, hangup and
+message URLs.
+
+Nesting rules are enforced by the type system: a Response only accepts
+ResponseElements, a GetDigits only accepts GetDigitsElements and so on.
+Attribute values are checked when the document is marshalled.
+
+  func answer(w http.ResponseWriter, r *http.Request) {
+    resp := plivoxml.NewResponse(
+      &plivoxml.Speak{Text: "Hello, world"},
+      &plivoxml.Dial{Children: []plivoxml.DialElement{
+        &plivoxml.Number{Number: "14155550100"},
+      }},
+    )
+    if err := resp.Write(w); err != nil {
+      log.Printf("answer: %v", err)
+    }
+  }
+*/
+package plivoxml
@@ -0,0 +1,355 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivoxml
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// Speak reads out text using text to speech.
+type Speak struct {
+	XMLName  xml.Name `xml:"Speak"`
+	Voice    string   `xml:"voice,attr,omitempty"`
+	Language string   `xml:"language,attr,omitempty"`
+	Loop     *int     `xml:"loop,attr,omitempty"`
+	Text     string   `xml:",chardata"`
+}
+
+func (e *Speak) Validate() error {
+	return firstError(
+		checkRequired("Speak", "text", e.Text),
+		checkOneOf("Speak", "voice", e.Voice, "WOMAN", "MAN"),
+		checkLoop("Speak", e.Loop),
+	)
+}
+
+func (*Speak) responseElement()  {}
+func (*Speak) getDigitsElement() {}
+func (*Speak) preAnswerElement() {}
+
+// Play plays an audio file.
+type Play struct {
+	XMLName xml.Name `xml:"Play"`
+	Loop    *int     `xml:"loop,attr,omitempty"`
+	URL     string   `xml:",chardata"`
+}
+
+func (e *Play) Validate() error {
+	return firstError(
+		checkRequired("Play", "url", e.URL),
+		checkLoop("Play", e.Loop),
+	)
+}
+
+func (*Play) responseElement()  {}
+func (*Play) getDigitsElement() {}
+func (*Play) preAnswerElement() {}
+
+// GetDigits collects digits entered by the caller.
+type GetDigits struct {
+	XMLName            xml.Name `xml:"GetDigits"`
+	Action             string   `xml:"action,attr,omitempty"`
+	Method             string   `xml:"method,attr,omitempty"`
+	Timeout            int      `xml:"timeout,attr,omitempty"`
+	DigitTimeout       int      `xml:"digitTimeout,attr,omitempty"`
+	FinishOnKey        string   `xml:"finishOnKey,attr,omitempty"`
+	NumDigits          int      `xml:"numDigits,attr,omitempty"`
+	Retries            int      `xml:"retries,attr,omitempty"`
+	Redirect           *bool    `xml:"redirect,attr,omitempty"`
+	PlayBeep           *bool    `xml:"playBeep,attr,omitempty"`
+	ValidDigits        string   `xml:"validDigits,attr,omitempty"`
+	InvalidDigitsSound string   `xml:"invalidDigitsSound,attr,omitempty"`
+	Log                *bool    `xml:"log,attr,omitempty"`
+	Children           []GetDigitsElement
+}
+
+func (e *GetDigits) Validate() error {
+	err := firstError(
+		checkMethod("GetDigits", "method", e.Method),
+		checkMin("GetDigits", "timeout", e.Timeout, 1),
+		checkMin("GetDigits", "digitTimeout", e.DigitTimeout, 1),
+		checkMin("GetDigits", "numDigits", e.NumDigits, 1),
+		checkMin("GetDigits", "retries", e.Retries, 1),
+		checkDigits("GetDigits", "validDigits", e.ValidDigits),
+	)
+	if err != nil {
+		return err
+	}
+	for _, c := range e.Children {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*GetDigits) responseElement()  {}
+func (*GetDigits) preAnswerElement() {}
+
+// Dial connects the call to one or more numbers or SIP endpoints.
+type Dial struct {
+	XMLName        xml.Name `xml:"Dial"`
+	Action         string   `xml:"action,attr,omitempty"`
+	Method         string   `xml:"method,attr,omitempty"`
+	HangupOnStar   *bool    `xml:"hangupOnStar,attr,omitempty"`
+	TimeLimit      int      `xml:"timeLimit,attr,omitempty"`
+	Timeout        int      `xml:"timeout,attr,omitempty"`
+	CallerID       string   `xml:"callerId,attr,omitempty"`
+	CallerName     string   `xml:"callerName,attr,omitempty"`
+	ConfirmSound   string   `xml:"confirmSound,attr,omitempty"`
+	ConfirmKey     string   `xml:"confirmKey,attr,omitempty"`
+	DialMusic      string   `xml:"dialMusic,attr,omitempty"`
+	CallbackURL    string   `xml:"callbackUrl,attr,omitempty"`
+	CallbackMethod string   `xml:"callbackMethod,attr,omitempty"`
+	Redirect       *bool    `xml:"redirect,attr,omitempty"`
+	DigitsMatch    string   `xml:"digitsMatch,attr,omitempty"`
+	SIPHeaders     string   `xml:"sipHeaders,attr,omitempty"`
+	Children       []DialElement
+}
+
+func (e *Dial) Validate() error {
+	err := firstError(
+		checkMethod("Dial", "method", e.Method),
+		checkMethod("Dial", "callbackMethod", e.CallbackMethod),
+		checkMin("Dial", "timeLimit", e.TimeLimit, 1),
+		checkMin("Dial", "timeout", e.Timeout, 1),
+		checkDigits("Dial", "confirmKey", e.ConfirmKey),
+	)
+	if err != nil {
+		return err
+	}
+	if len(e.Children) == 0 {
+		return &AttributeError{"Dial", "children", "none"}
+	}
+	for _, c := range e.Children {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*Dial) responseElement() {}
+
+// Number is a phone number dialled by Dial.
+type Number struct {
+	XMLName         xml.Name `xml:"Number"`
+	SendDigits      string   `xml:"sendDigits,attr,omitempty"`
+	SendOnPreanswer *bool    `xml:"sendOnPreanswer,attr,omitempty"`
+	Number          string   `xml:",chardata"`
+}
+
+func (e *Number) Validate() error {
+	return firstError(
+		checkRequired("Number", "number", e.Number),
+		checkDigits("Number", "sendDigits", e.SendDigits),
+	)
+}
+
+func (*Number) dialElement() {}
+
+// User is a SIP endpoint dialled by Dial.
+type User struct {
+	XMLName         xml.Name `xml:"User"`
+	SendDigits      string   `xml:"sendDigits,attr,omitempty"`
+	SendOnPreanswer *bool    `xml:"sendOnPreanswer,attr,omitempty"`
+	SIPHeaders      string   `xml:"sipHeaders,attr,omitempty"`
+	URI             string   `xml:",chardata"`
+}
+
+func (e *User) Validate() error {
+	if !strings.HasPrefix(e.URI, "sip:") {
+		return &AttributeError{"User", "uri", e.URI}
+	}
+	return checkDigits("User", "sendDigits", e.SendDigits)
+}
+
+func (*User) dialElement() {}
+
+// Conference joins the call to a conference room.
+type Conference struct {
+	XMLName                xml.Name `xml:"Conference"`
+	Muted                  *bool    `xml:"muted,attr,omitempty"`
+	EnterSound             string   `xml:"enterSound,attr,omitempty"`
+	ExitSound              string   `xml:"exitSound,attr,omitempty"`
+	StartConferenceOnEnter *bool    `xml:"startConferenceOnEnter,attr,omitempty"`
+	EndConferenceOnExit    *bool    `xml:"endConferenceOnExit,attr,omitempty"`
+	StayAlone              *bool    `xml:"stayAlone,attr,omitempty"`
+	WaitSound              string   `xml:"waitSound,attr,omitempty"`
+	MaxMembers             int      `xml:"maxMembers,attr,omitempty"`
+	Record                 *bool    `xml:"record,attr,omitempty"`
+	RecordFileFormat       string   `xml:"recordFileFormat,attr,omitempty"`
+	TimeLimit              int      `xml:"timeLimit,attr,omitempty"`
+	HangupOnStar           *bool    `xml:"hangupOnStar,attr,omitempty"`
+	Action                 string   `xml:"action,attr,omitempty"`
+	Method                 string   `xml:"method,attr,omitempty"`
+	CallbackURL            string   `xml:"callbackUrl,attr,omitempty"`
+	CallbackMethod         string   `xml:"callbackMethod,attr,omitempty"`
+	DigitsMatch            string   `xml:"digitsMatch,attr,omitempty"`
+	FloorEvent             *bool    `xml:"floorEvent,attr,omitempty"`
+	Name                   string   `xml:",chardata"`
+}
+
+func (e *Conference) Validate() error {
+	err := firstError(
+		checkRequired("Conference", "name", e.Name),
+		checkMethod("Conference", "method", e.Method),
+		checkMethod("Conference", "callbackMethod", e.CallbackMethod),
+		checkOneOf("Conference", "recordFileFormat", e.RecordFileFormat, "mp3", "wav"),
+		checkMin("Conference", "maxMembers", e.MaxMembers, 1),
+		checkMin("Conference", "timeLimit", e.TimeLimit, 1),
+	)
+	if err == nil && e.MaxMembers > 200 {
+		err = &AttributeError{"Conference", "maxMembers", e.MaxMembers}
+	}
+	return err
+}
+
+func (*Conference) responseElement() {}
+
+// Record records the call.
+type Record struct {
+	XMLName             xml.Name `xml:"Record"`
+	Action              string   `xml:"action,attr,omitempty"`
+	Method              string   `xml:"method,attr,omitempty"`
+	FileFormat          string   `xml:"fileFormat,attr,omitempty"`
+	Redirect            *bool    `xml:"redirect,attr,omitempty"`
+	Timeout             int      `xml:"timeout,attr,omitempty"`
+	MaxLength           int      `xml:"maxLength,attr,omitempty"`
+	PlayBeep            *bool    `xml:"playBeep,attr,omitempty"`
+	FinishOnKey         string   `xml:"finishOnKey,attr,omitempty"`
+	RecordSession       *bool    `xml:"recordSession,attr,omitempty"`
+	StartOnDialAnswer   *bool    `xml:"startOnDialAnswer,attr,omitempty"`
+	TranscriptionType   string   `xml:"transcriptionType,attr,omitempty"`
+	TranscriptionURL    string   `xml:"transcriptionUrl,attr,omitempty"`
+	TranscriptionMethod string   `xml:"transcriptionMethod,attr,omitempty"`
+	CallbackURL         string   `xml:"callbackUrl,attr,omitempty"`
+	CallbackMethod      string   `xml:"callbackMethod,attr,omitempty"`
+}
+
+func (e *Record) Validate() error {
+	return firstError(
+		checkMethod("Record", "method", e.Method),
+		checkMethod("Record", "transcriptionMethod", e.TranscriptionMethod),
+		checkMethod("Record", "callbackMethod", e.CallbackMethod),
+		checkOneOf("Record", "fileFormat", e.FileFormat, "mp3", "wav"),
+		checkOneOf("Record", "transcriptionType", e.TranscriptionType, "auto", "hybrid"),
+		checkMin("Record", "timeout", e.Timeout, 1),
+		checkMin("Record", "maxLength", e.MaxLength, 1),
+	)
+}
+
+func (*Record) responseElement() {}
+
+// Redirect transfers control of the call to another XML document.
+type Redirect struct {
+	XMLName xml.Name `xml:"Redirect"`
+	Method  string   `xml:"method,attr,omitempty"`
+	URL     string   `xml:",chardata"`
+}
+
+func (e *Redirect) Validate() error {
+	return firstError(
+		checkRequired("Redirect", "url", e.URL),
+		checkMethod("Redirect", "method", e.Method),
+	)
+}
+
+func (*Redirect) responseElement()  {}
+func (*Redirect) preAnswerElement() {}
+
+// Wait pauses the call.
+type Wait struct {
+	XMLName    xml.Name `xml:"Wait"`
+	Length     int      `xml:"length,attr,omitempty"`
+	Silence    *bool    `xml:"silence,attr,omitempty"`
+	MinSilence int      `xml:"minSilence,attr,omitempty"`
+	Beep       *bool    `xml:"beep,attr,omitempty"`
+}
+
+func (e *Wait) Validate() error {
+	return firstError(
+		checkMin("Wait", "length", e.Length, 1),
+		checkMin("Wait", "minSilence", e.MinSilence, 1),
+	)
+}
+
+func (*Wait) responseElement()  {}
+func (*Wait) getDigitsElement() {}
+func (*Wait) preAnswerElement() {}
+
+// Hangup ends the call.
+type Hangup struct {
+	XMLName  xml.Name `xml:"Hangup"`
+	Reason   string   `xml:"reason,attr,omitempty"`
+	Schedule int      `xml:"schedule,attr,omitempty"`
+}
+
+func (e *Hangup) Validate() error {
+	return firstError(
+		checkOneOf("Hangup", "reason", e.Reason, "rejected", "busy"),
+		checkMin("Hangup", "schedule", e.Schedule, 1),
+	)
+}
+
+func (*Hangup) responseElement() {}
+
+// PreAnswer runs its children before the call is answered.
+type PreAnswer struct {
+	XMLName  xml.Name `xml:"PreAnswer"`
+	Children []PreAnswerElement
+}
+
+func (e *PreAnswer) Validate() error {
+	for _, c := range e.Children {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (*PreAnswer) responseElement() {}
+
+// Message sends an SMS.
+type Message struct {
+	XMLName        xml.Name `xml:"Message"`
+	Src            string   `xml:"src,attr"`
+	Dst            string   `xml:"dst,attr"`
+	Type           string   `xml:"type,attr,omitempty"`
+	CallbackURL    string   `xml:"callbackUrl,attr,omitempty"`
+	CallbackMethod string   `xml:"callbackMethod,attr,omitempty"`
+	Text           string   `xml:",chardata"`
+}
+
+func (e *Message) Validate() error {
+	return firstError(
+		checkRequired("Message", "src", e.Src),
+		checkRequired("Message", "dst", e.Dst),
+		checkRequired("Message", "text", e.Text),
+		checkOneOf("Message", "type", e.Type, "sms"),
+		checkMethod("Message", "callbackMethod", e.CallbackMethod),
+	)
+}
+
+func (*Message) responseElement()  {}
+func (*Message) preAnswerElement() {}
+
+// DTMF sends digits on the call.
+type DTMF struct {
+	XMLName xml.Name `xml:"DTMF"`
+	Async   *bool    `xml:"async,attr,omitempty"`
+	Digits  string   `xml:",chardata"`
+}
+
+func (e *DTMF) Validate() error {
+	return firstError(
+		checkRequired("DTMF", "digits", e.Digits),
+		checkDigits("DTMF", "digits", e.Digits),
+	)
+}
+
+func (*DTMF) responseElement()  {}
+func (*DTMF) preAnswerElement() {}
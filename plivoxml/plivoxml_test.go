@@ -0,0 +1,70 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivoxml
+
+import "testing"
+
+func TestResponseMarshal(t *testing.T) {
+	r := NewResponse(
+		&PreAnswer{Children: []PreAnswerElement{&Play{URL: "http://example.com/ring.mp3"}}},
+		&GetDigits{Action: "http://example.com/digits", Method: "POST", NumDigits: 1, Redirect: Bool(false),
+			Children: []GetDigitsElement{&Speak{Voice: "WOMAN", Loop: Int(0), Text: "Press 1 & wait"}}},
+		&Dial{CallerID: "14155550100", Children: []DialElement{
+			&Number{SendDigits: "ww12", Number: "14155550101"},
+			&User{URI: "sip:alice@phone.plivo.com"},
+		}},
+		&Wait{Length: 2},
+		&Message{Src: "14155550100", Dst: "14155550101", Text: "Bye"},
+		&Hangup{Reason: "busy"},
+	)
+	want := `<Response>` +
+		`<PreAnswer><Play>http://example.com/ring.mp3</Play></PreAnswer>` +
+		`<GetDigits action="http://example.com/digits" method="POST" numDigits="1" redirect="false">` +
+		`<Speak voice="WOMAN" loop="0">Press 1 &amp; wait</Speak></GetDigits>` +
+		`<Dial callerId="14155550100"><Number sendDigits="ww12">14155550101</Number>` +
+		`<User>sip:alice@phone.plivo.com</User></Dial>` +
+		`<Wait length="2"></Wait>` +
+		`<Message src="14155550100" dst="14155550101">Bye</Message>` +
+		`<Hangup reason="busy"></Hangup>` +
+		`</Response>`
+	b, err := r.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(b) != want {
+		t.Errorf("Marshal returned\n%s\nwant\n%s", b, want)
+	}
+}
+
+func TestResponseValidate(t *testing.T) {
+	invalid := []ResponseElement{
+		&Speak{},
+		&Speak{Voice: "ROBOT", Text: "Hi"},
+		&Play{URL: "http://example.com/a.mp3", Loop: Int(-1)},
+		&GetDigits{Method: "PUT"},
+		&GetDigits{Children: []GetDigitsElement{&Play{}}},
+		&Dial{},
+		&Dial{Children: []DialElement{&User{URI: "alice"}}},
+		&Conference{Name: "room", MaxMembers: 500},
+		&Record{FileFormat: "ogg"},
+		&Redirect{},
+		&Hangup{Reason: "bored"},
+		&Message{Src: "1", Dst: "2", Text: "Hi", Type: "mms"},
+		&DTMF{Digits: "12x"},
+	}
+	for _, e := range invalid {
+		if _, err := NewResponse(e).Marshal(); err == nil {
+			t.Errorf("Marshal accepted invalid %#v", e)
+		}
+	}
+}
+
+func TestResponseString(t *testing.T) {
+	if s := NewResponse(&Wait{Length: 1}).String(); s != `<Response><Wait length="1"></Wait></Response>` {
+		t.Errorf("String = %q", s)
+	}
+	if s := NewResponse(&Speak{}).String(); s != `%!(plivoxml: invalid Speak attribute text="")` {
+		t.Errorf("String of an invalid response = %q", s)
+	}
+}
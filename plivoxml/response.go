@@ -0,0 +1,184 @@
+// Public Domain (-) 2013-2014 The GoPlivo Authors.
+// See the GoPlivo UNLICENSE file for details.
+
+package plivoxml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Element is implemented by every Plivo XML element.
+type Element interface {
+	// Validate checks the element's attributes and children.
+	Validate() error
+}
+
+// ResponseElement is an element allowed directly under Response.
+type ResponseElement interface {
+	Element
+	responseElement()
+}
+
+// GetDigitsElement is an element allowed under GetDigits.
+type GetDigitsElement interface {
+	Element
+	getDigitsElement()
+}
+
+// PreAnswerElement is an element allowed under PreAnswer.
+type PreAnswerElement interface {
+	Element
+	preAnswerElement()
+}
+
+// DialElement is an element allowed under Dial.
+type DialElement interface {
+	Element
+	dialElement()
+}
+
+// Response is the root of every Plivo XML document.
+type Response struct {
+	XMLName  xml.Name `xml:"Response"`
+	Children []ResponseElement
+}
+
+// NewResponse returns a Response holding the given elements.
+func NewResponse(elems ...ResponseElement) *Response {
+	return &Response{Children: elems}
+}
+
+// Add appends elements to the response and returns it.
+func (r *Response) Add(elems ...ResponseElement) *Response {
+	r.Children = append(r.Children, elems...)
+	return r
+}
+
+// Validate checks every element of the response.
+func (r *Response) Validate() error {
+	for _, e := range r.Children {
+		if err := e.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Marshal validates the response and returns its XML encoding.
+func (r *Response) Marshal() ([]byte, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return xml.Marshal(r)
+}
+
+// String returns the XML encoding of the response for display. If the
+// response is invalid, it returns the validation error formatted as
+// "%!(error)" instead. Use Marshal or Write to produce a document, since they
+// return the error.
+func (r *Response) String() string {
+	b, err := r.Marshal()
+	if err != nil {
+		return fmt.Sprintf("%%!(%v)", err)
+	}
+	return string(b)
+}
+
+// Write marshals the response and writes it to w with the XML content type.
+func (r *Response) Write(w http.ResponseWriter) error {
+	b, err := r.Marshal()
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	_, err = w.Write(b)
+	return err
+}
+
+// Bool returns a pointer to v, for optional boolean attributes.
+func Bool(v bool) *bool {
+	return &v
+}
+
+// Int returns a pointer to v, for optional integer attributes where zero is meaningful.
+func Int(v int) *int {
+	return &v
+}
+
+// An AttributeError reports an invalid attribute value.
+type AttributeError struct {
+	Element   string
+	Attribute string
+	Value     interface{}
+}
+
+func (e *AttributeError) Error() string {
+	return fmt.Sprintf("plivoxml: invalid %s attribute %s=%v", e.Element, e.Attribute, e.Value)
+}
+
+// checkRequired validates that a mandatory attribute or body is set.
+func checkRequired(elem, attr, v string) error {
+	if v == "" {
+		return &AttributeError{elem, attr, `""`}
+	}
+	return nil
+}
+
+// checkDigits validates a string of DTMF digits, where w and W are pauses.
+func checkDigits(elem, attr, v string) error {
+	if strings.Trim(v, "0123456789*#wW") != "" {
+		return &AttributeError{elem, attr, v}
+	}
+	return nil
+}
+
+// checkMethod validates an HTTP method attribute.
+func checkMethod(elem, attr, v string) error {
+	switch v {
+	case "", "GET", "POST":
+		return nil
+	}
+	return &AttributeError{elem, attr, v}
+}
+
+// checkOneOf validates an enumerated attribute.
+func checkOneOf(elem, attr, v string, allowed ...string) error {
+	if v == "" {
+		return nil
+	}
+	for _, a := range allowed {
+		if v == a {
+			return nil
+		}
+	}
+	return &AttributeError{elem, attr, v}
+}
+
+// checkMin validates that a numeric attribute, when set, is at least min.
+func checkMin(elem, attr string, v, min int) error {
+	if v != 0 && v < min {
+		return &AttributeError{elem, attr, v}
+	}
+	return nil
+}
+
+// checkLoop validates a loop attribute, where zero means forever.
+func checkLoop(elem string, v *int) error {
+	if v != nil && *v < 0 {
+		return &AttributeError{elem, "loop", *v}
+	}
+	return nil
+}
+
+// firstError returns the first non-nil error.
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}